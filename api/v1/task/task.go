@@ -2,22 +2,38 @@ package task
 
 import (
 	"strconv"
+	"strings"
+	"time"
 	
 	"github.com/gin-gonic/gin"
 	
 	"tg_manager_api/global"
 	"tg_manager_api/model/response"
 	"tg_manager_api/services/task"
+	"tg_manager_api/services/task/service"
 	"tg_manager_api/utils"
 )
 
 // CreateTaskRequest 创建任务请求
 type CreateTaskRequest struct {
-	TaskType  string                 `json:"task_type" binding:"required"`  // 任务类型: send_message, join_group, add_contact等
+	TaskType  string                 `json:"task_type" binding:"required"`  // 任务类型，可选值见 /api/v1/task-types
 	AccountID uint                   `json:"account_id" binding:"required"` // 关联的账号ID
 	Params    map[string]interface{} `json:"params" binding:"required"`     // 任务参数，JSON格式
 	Priority  *int                   `json:"priority"`                      // 优先级，可选
 	TimeoutSec *int                  `json:"timeout_sec"`                   // 超时时间，可选
+	RunAt     *time.Time             `json:"run_at"`                        // 计划执行时间，可选，为空表示立即执行
+	
+	// 重试设置，可选
+	MaxAttempts        *int     `json:"max_attempts"`          // 最大尝试次数，1表示不重试
+	RetryBackoffSec    *int     `json:"retry_backoff_sec"`     // 重试退避基数(秒)
+	RetryBackoffMaxSec *int     `json:"retry_backoff_max_sec"` // 重试退避上限(秒)
+	RetryableErrors    []string `json:"retryable_errors"`      // 可重试的错误类型，为空表示所有错误均可重试
+	
+	// 所需的工作节点标签，如 region:eu、proxy:socks5，可选
+	RequiredTags []string `json:"required_tags"`
+	
+	// 幂等键，可选，也可通过Idempotency-Key请求头传入
+	IdempotencyKey string `json:"idempotency_key"`
 }
 
 // TaskController 任务控制器
@@ -29,6 +45,7 @@ type TaskController struct{}
 // @Tags Task
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键，重复请求返回首次创建的任务，未配置Redis时携带幂等键的请求会被拒绝"
 // @Param data body CreateTaskRequest true "创建任务的数据"
 // @Success 200 {object} response.Response{data=model.Task} "创建成功"
 // @Router /api/v1/task [post]
@@ -42,64 +59,135 @@ func (ctrl *TaskController) CreateTask(c *gin.Context) {
 	// 获取任务服务
 	taskService := task.GetTaskServiceFromContext(c)
 	
+	// 请求头中的幂等键优先
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if idempotencyKey == "" {
+		idempotencyKey = req.IdempotencyKey
+	}
+	
+	// 可选参数在创建时一并写入，避免任务在更新前被调度
+	opts := &service.CreateTaskOptions{
+		Priority:           req.Priority,
+		TimeoutSec:         req.TimeoutSec,
+		RunAt:              req.RunAt,
+		MaxAttempts:        req.MaxAttempts,
+		RetryBackoffSec:    req.RetryBackoffSec,
+		RetryBackoffMaxSec: req.RetryBackoffMaxSec,
+		RetryableErrors:    req.RetryableErrors,
+		RequiredTags:       req.RequiredTags,
+		IdempotencyKey:     idempotencyKey,
+	}
+	
 	// 创建任务
-	newTask, err := taskService.CreateTask(c, req.TaskType, req.AccountID, req.Params)
+	newTask, err := taskService.CreateTask(c, req.TaskType, req.AccountID, req.Params, opts)
 	if err != nil {
-		response.FailWithMessage("创建任务失败: "+err.Error(), c)
+		failWithTaskError("创建任务失败", err, c)
 		return
 	}
 	
-	// 如果提供了可选参数，更新任务
-	if req.Priority != nil || req.TimeoutSec != nil {
-		updates := map[string]interface{}{}
-		
-		if req.Priority != nil {
-			updates["priority"] = *req.Priority
-		}
-		
-		if req.TimeoutSec != nil {
-			updates["timeout_sec"] = *req.TimeoutSec
-		}
-		
-		// 如果有额外参数需要更新
-		if len(updates) > 0 {
-			if err := global.DB.Model(&newTask).Updates(updates).Error; err != nil {
-				// 继续流程，仅记录更新失败，不影响任务创建
-				global.LOG.Error("更新任务优先级或超时时间失败: " + err.Error())
-			}
-		}
-	}
-	
 	response.OkWithData(newTask, c)
 }
 
+// TaskListRequest 任务列表查询参数
+type TaskListRequest struct {
+	Status         string     `form:"status"`                                                  // 任务状态，多个状态以逗号分隔
+	TaskType       string     `form:"task_type"`                                               // 任务类型
+	AccountID      uint       `form:"account_id"`                                              // 账号ID
+	AccountGroupID uint       `form:"account_group_id"`                                        // 账号分组ID
+	WorkerID       string     `form:"worker_id"`                                               // 工作节点ID
+	MinPriority    *int       `form:"min_priority"`                                            // 最低优先级
+	MaxPriority    *int       `form:"max_priority"`                                            // 最高优先级
+	CreatedFrom    *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`   // 创建时间下限
+	CreatedTo      *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`     // 创建时间上限
+	CompletedFrom  *time.Time `form:"completed_from" time_format:"2006-01-02T15:04:05Z07:00"` // 完成时间下限
+	CompletedTo    *time.Time `form:"completed_to" time_format:"2006-01-02T15:04:05Z07:00"`   // 完成时间上限
+	SortBy         string     `form:"sort_by"`                                                 // 排序字段: created_at, priority, completed_at
+	SortOrder      string     `form:"sort_order"`                                              // 排序方向: asc, desc，默认desc
+}
+
 // GetTaskList 获取任务列表
 // @Summary 获取任务列表
-// @Description 分页获取任务列表
+// @Description 按条件查询任务列表。传入cursor参数时使用游标分页(首页传空值)，返回next_cursor且不统计总数；否则使用页码分页
 // @Tags Task
 // @Accept json
 // @Produce json
+// @Param status query string false "任务状态，多个状态以逗号分隔"
+// @Param task_type query string false "任务类型"
+// @Param account_id query uint false "账号ID"
+// @Param account_group_id query uint false "账号分组ID"
+// @Param worker_id query string false "工作节点ID"
+// @Param min_priority query int false "最低优先级"
+// @Param max_priority query int false "最高优先级"
+// @Param created_from query string false "创建时间下限(RFC3339)"
+// @Param created_to query string false "创建时间上限(RFC3339)"
+// @Param completed_from query string false "完成时间下限(RFC3339)"
+// @Param completed_to query string false "完成时间上限(RFC3339)"
+// @Param sort_by query string false "排序字段: created_at, priority, completed_at" default(created_at)
+// @Param sort_order query string false "排序方向: asc, desc" default(desc)
+// @Param cursor query string false "游标，来自上一页的next_cursor"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
 // @Success 200 {object} response.Response{data=response.PageResult{list=[]model.Task}} "获取成功"
 // @Router /api/v1/tasks [get]
 func (ctrl *TaskController) GetTaskList(c *gin.Context) {
+	var req TaskListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage("参数错误: "+err.Error(), c)
+		return
+	}
+	if req.SortOrder != "" && req.SortOrder != "asc" && req.SortOrder != "desc" {
+		response.FailWithMessage("参数错误: sort_order只能为asc或desc", c)
+		return
+	}
+	
 	// 获取分页参数
 	page, pageSize := utils.GetPage(c)
 	
+	query := &service.TaskQuery{
+		TaskType:       req.TaskType,
+		AccountID:      req.AccountID,
+		AccountGroupID: req.AccountGroupID,
+		WorkerID:       req.WorkerID,
+		MinPriority:    req.MinPriority,
+		MaxPriority:    req.MaxPriority,
+		CreatedFrom:    req.CreatedFrom,
+		CreatedTo:      req.CreatedTo,
+		CompletedFrom:  req.CompletedFrom,
+		CompletedTo:    req.CompletedTo,
+		SortBy:         req.SortBy,
+		SortDesc:       req.SortOrder != "asc",
+		Page:           page,
+		PageSize:       pageSize,
+	}
+	if req.Status != "" {
+		query.Statuses = strings.Split(req.Status, ",")
+	}
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		query.Cursor = &cursor
+	}
+	
 	// 获取任务服务
 	taskService := task.GetTaskServiceFromContext(c)
 	
 	// 获取任务列表
-	tasks, total, err := taskService.GetTasks(c, page, pageSize)
+	result, err := taskService.GetTasks(c, query)
 	if err != nil {
 		response.FailWithMessage("获取任务列表失败: "+err.Error(), c)
 		return
 	}
 	
+	if query.Cursor != nil {
+		response.OkWithDetailed(response.CursorPageResult{
+			List:       result.List,
+			NextCursor: result.NextCursor,
+			PageSize:   pageSize,
+		}, "获取成功", c)
+		return
+	}
+	
 	response.OkWithDetailed(response.PageResult{
-		List:     tasks,
-		Total:    total,
+		List:     result.List,
+		Total:    result.Total,
 		Page:     page,
 		PageSize: pageSize,
 	}, "获取成功", c)
@@ -133,7 +221,7 @@ func (ctrl *TaskController) GetTaskDetail(c *gin.Context) {
 
 // CancelTask 取消任务
 // @Summary 取消任务
-// @Description 取消指定的任务
+// @Description 取消指定的任务，执行中的任务先进入canceling状态，待工作节点确认后完成取消
 // @Tags Task
 // @Accept json
 // @Produce json
@@ -156,6 +244,32 @@ func (ctrl *TaskController) CancelTask(c *gin.Context) {
 	response.OkWithMessage("取消任务成功", c)
 }
 
+// GetTaskTimeline 获取任务状态时间线
+// @Summary 获取任务状态时间线
+// @Description 获取任务从创建开始的所有状态变化，包括原状态、新状态、操作者、原因和时间
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 200 {object} response.Response{data=[]model.TaskStatusHistory} "获取成功"
+// @Router /api/v1/tasks/{id}/timeline [get]
+func (ctrl *TaskController) GetTaskTimeline(c *gin.Context) {
+	// 获取任务ID
+	taskID := c.Param("id")
+	
+	// 获取任务服务
+	taskService := task.GetTaskServiceFromContext(c)
+	
+	// 获取任务状态时间线
+	timeline, err := taskService.GetTaskTimeline(c, taskID)
+	if err != nil {
+		response.FailWithMessage("获取任务状态时间线失败: "+err.Error(), c)
+		return
+	}
+	
+	response.OkWithData(timeline, c)
+}
+
 // GetTasksByAccount 获取账号关联的任务
 // @Summary 获取账号关联的任务
 // @Description 获取指定账号关联的所有任务
@@ -212,14 +326,15 @@ func (ctrl *TaskController) GetTasksByAccount(c *gin.Context) {
 
 // GetTaskLogs 获取任务日志
 // @Summary 获取任务日志
-// @Description 获取指定任务的执行日志
+// @Description 获取指定任务的执行日志，包括工作节点上报的日志和任务生命周期日志
 // @Tags Task
 // @Accept json
 // @Produce json
 // @Param id path string true "任务ID"
+// @Param level query string false "最低日志级别: debug, info, warn, error"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
-// @Success 200 {object} response.Response{data=response.PageResult{list=[]string}} "获取成功"
+// @Success 200 {object} response.Response{data=response.PageResult{list=[]model.TaskLog}} "获取成功"
 // @Router /api/v1/task/{id}/logs [get]
 func (ctrl *TaskController) GetTaskLogs(c *gin.Context) {
 	// 获取任务ID
@@ -232,7 +347,7 @@ func (ctrl *TaskController) GetTaskLogs(c *gin.Context) {
 	taskService := task.GetTaskServiceFromContext(c)
 	
 	// 获取任务日志
-	logs, total, err := taskService.GetTaskLogs(c, taskID, page, pageSize)
+	logs, total, err := taskService.GetTaskLogs(c, taskID, c.Query("level"), page, pageSize)
 	if err != nil {
 		response.FailWithMessage("获取任务日志失败: "+err.Error(), c)
 		return
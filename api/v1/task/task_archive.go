@@ -0,0 +1,27 @@
+package task
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task/service"
+)
+
+// GetArchivedTask 查询已归档的任务
+// @Summary 查询已归档的任务
+// @Description 超过保留期的已结束任务会从任务列表中移除，可按任务ID查询其归档的任务、分配记录、执行记录、状态历史和日志
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param task_id path string true "任务ID"
+// @Success 200 {object} response.Response{data=service.ArchivedTask} "获取成功"
+// @Router /api/v1/task-archives/{task_id} [get]
+func (ctrl *TaskController) GetArchivedTask(c *gin.Context) {
+	archived, err := service.GetArchivedTask(c, c.Param("task_id"))
+	if err != nil {
+		response.FailWithMessage("获取归档任务失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithData(archived, c)
+}
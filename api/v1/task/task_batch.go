@@ -0,0 +1,108 @@
+package task
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task"
+	"tg_manager_api/services/task/service"
+)
+
+// CreateTaskBatchRequest 批量创建任务请求
+type CreateTaskBatchRequest struct {
+	TaskType        string                 `json:"task_type" binding:"required"` // 任务类型
+	AccountGroupID  uint                   `json:"account_group_id"`             // 目标账号分组ID，与account_ids至少填写一个
+	AccountIDs      []uint                 `json:"account_ids"`                  // 目标账号ID列表
+	AccountStatuses []string               `json:"account_statuses"`             // 允许的账号状态，默认仅ACTIVE
+	Params          map[string]interface{} `json:"params" binding:"required"`    // 任务参数模板，JSON格式
+	Priority        *int                   `json:"priority"`                     // 优先级，可选
+	TimeoutSec      *int                   `json:"timeout_sec"`                  // 超时时间，可选
+	RunAt           *time.Time             `json:"run_at"`                       // 计划执行时间，可选
+
+	// 重试设置，可选
+	MaxAttempts        *int     `json:"max_attempts"`          // 最大尝试次数，1表示不重试
+	RetryBackoffSec    *int     `json:"retry_backoff_sec"`     // 重试退避基数(秒)
+	RetryBackoffMaxSec *int     `json:"retry_backoff_max_sec"` // 重试退避上限(秒)
+	RetryableErrors    []string `json:"retryable_errors"`      // 可重试的错误类型
+
+	// 所需的工作节点标签，可选
+	RequiredTags []string `json:"required_tags"`
+}
+
+// TaskBatchController 批量任务控制器
+type TaskBatchController struct{}
+
+// CreateBatch 批量创建任务
+// @Summary 批量创建任务
+// @Description 为账号分组或账号列表中每个符合状态要求的账号创建一个任务
+// @Tags TaskBatch
+// @Accept json
+// @Produce json
+// @Param data body CreateTaskBatchRequest true "批量任务数据"
+// @Success 200 {object} response.Response{data=model.TaskBatch} "创建成功"
+// @Router /api/v1/task-batches [post]
+func (ctrl *TaskBatchController) CreateBatch(c *gin.Context) {
+	var req CreateTaskBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage("参数错误: "+err.Error(), c)
+		return
+	}
+
+	spec := &service.TaskBatchSpec{
+		TaskType:        req.TaskType,
+		AccountGroupID:  req.AccountGroupID,
+		AccountIDs:      req.AccountIDs,
+		AccountStatuses: req.AccountStatuses,
+		Params:          req.Params,
+		Options: &service.CreateTaskOptions{
+			Priority:           req.Priority,
+			TimeoutSec:         req.TimeoutSec,
+			RunAt:              req.RunAt,
+			MaxAttempts:        req.MaxAttempts,
+			RetryBackoffSec:    req.RetryBackoffSec,
+			RetryBackoffMaxSec: req.RetryBackoffMaxSec,
+			RetryableErrors:    req.RetryableErrors,
+			RequiredTags:       req.RequiredTags,
+		},
+	}
+
+	// 获取批量任务服务
+	batchService := task.GetTaskBatchServiceFromContext(c)
+
+	// 批量创建任务
+	batch, err := batchService.CreateBatch(c, spec)
+	if err != nil {
+		failWithTaskError("批量创建任务失败", err, c)
+		return
+	}
+
+	response.OkWithData(batch, c)
+}
+
+// GetBatchProgress 获取批量任务进度
+// @Summary 获取批量任务进度
+// @Description 按子任务状态汇总批量任务的执行进度
+// @Tags TaskBatch
+// @Accept json
+// @Produce json
+// @Param id path string true "批次ID"
+// @Success 200 {object} response.Response{data=service.TaskBatchProgress} "获取成功"
+// @Router /api/v1/task-batches/{id} [get]
+func (ctrl *TaskBatchController) GetBatchProgress(c *gin.Context) {
+	// 获取批次ID
+	batchID := c.Param("id")
+
+	// 获取批量任务服务
+	batchService := task.GetTaskBatchServiceFromContext(c)
+
+	// 获取批量任务进度
+	progress, err := batchService.GetBatchProgress(c, batchID)
+	if err != nil {
+		response.FailWithMessage("获取批量任务进度失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithData(progress, c)
+}
@@ -0,0 +1,151 @@
+package task
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task"
+	"tg_manager_api/services/task/service"
+)
+
+// TaskPauseResponse 批量暂停或恢复任务的结果
+type TaskPauseResponse struct {
+	Count int `json:"count"` // 暂停或恢复的任务数
+}
+
+// PauseTask 暂停任务
+// @Summary 暂停任务
+// @Description 暂停等待调度或等待上游的任务，恢复前不会被分配，已分配的任务不受影响
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 200 {object} response.Response "暂停成功"
+// @Router /api/v1/tasks/{id}/pause [post]
+func (ctrl *TaskController) PauseTask(c *gin.Context) {
+	taskService := task.GetTaskServiceFromContext(c)
+	if err := taskService.PauseTask(c, c.Param("id")); err != nil {
+		response.FailWithMessage("暂停任务失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithMessage("暂停任务成功", c)
+}
+
+// ResumeTask 恢复任务
+// @Summary 恢复任务
+// @Description 恢复已暂停的任务，任务按原有优先级和创建时间重新参与调度，上游任务尚未全部完成的工作流任务恢复为等待上游状态
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 200 {object} response.Response "恢复成功"
+// @Router /api/v1/tasks/{id}/resume [post]
+func (ctrl *TaskController) ResumeTask(c *gin.Context) {
+	taskService := task.GetTaskServiceFromContext(c)
+	if err := taskService.ResumeTask(c, c.Param("id")); err != nil {
+		response.FailWithMessage("恢复任务失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithMessage("恢复任务成功", c)
+}
+
+// PauseAccountTasks 暂停账号的任务
+// @Summary 暂停账号的任务
+// @Description 暂停账号下所有等待调度的任务，恢复前该账号新建、重新排队和被释放的工作流任务也不会被分配
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param account_id path uint true "账号ID"
+// @Success 200 {object} response.Response{data=TaskPauseResponse} "暂停成功"
+// @Router /api/v1/accounts/{account_id}/tasks/pause [post]
+func (ctrl *TaskController) PauseAccountTasks(c *gin.Context) {
+	accountID, err := strconv.ParseUint(c.Param("account_id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage("无效的账号ID", c)
+		return
+	}
+
+	ctrl.pauseTasks(c, service.TaskPauseScope{AccountID: uint(accountID)}, true)
+}
+
+// ResumeAccountTasks 恢复账号的任务
+// @Summary 恢复账号的任务
+// @Description 恢复账号下所有已暂停的任务，账号所在分组仍暂停时任务保持暂停
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param account_id path uint true "账号ID"
+// @Success 200 {object} response.Response{data=TaskPauseResponse} "恢复成功"
+// @Router /api/v1/accounts/{account_id}/tasks/resume [post]
+func (ctrl *TaskController) ResumeAccountTasks(c *gin.Context) {
+	accountID, err := strconv.ParseUint(c.Param("account_id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage("无效的账号ID", c)
+		return
+	}
+
+	ctrl.pauseTasks(c, service.TaskPauseScope{AccountID: uint(accountID)}, false)
+}
+
+// PauseAccountGroupTasks 暂停账号分组的任务
+// @Summary 暂停账号分组的任务
+// @Description 暂停账号分组内所有账号等待调度的任务，恢复前分组内新建、重新排队和被释放的工作流任务也不会被分配
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param id path uint true "账号分组ID"
+// @Success 200 {object} response.Response{data=TaskPauseResponse} "暂停成功"
+// @Router /api/v1/account-groups/{id}/tasks/pause [post]
+func (ctrl *TaskController) PauseAccountGroupTasks(c *gin.Context) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage("无效的账号分组ID", c)
+		return
+	}
+
+	ctrl.pauseTasks(c, service.TaskPauseScope{AccountGroupID: uint(groupID)}, true)
+}
+
+// ResumeAccountGroupTasks 恢复账号分组的任务
+// @Summary 恢复账号分组的任务
+// @Description 恢复账号分组内所有账号已暂停的任务，单独暂停的账号的任务保持暂停
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param id path uint true "账号分组ID"
+// @Success 200 {object} response.Response{data=TaskPauseResponse} "恢复成功"
+// @Router /api/v1/account-groups/{id}/tasks/resume [post]
+func (ctrl *TaskController) ResumeAccountGroupTasks(c *gin.Context) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage("无效的账号分组ID", c)
+		return
+	}
+
+	ctrl.pauseTasks(c, service.TaskPauseScope{AccountGroupID: uint(groupID)}, false)
+}
+
+// 批量暂停或恢复范围内的任务
+func (ctrl *TaskController) pauseTasks(c *gin.Context, scope service.TaskPauseScope, pause bool) {
+	taskService := task.GetTaskServiceFromContext(c)
+	if pause {
+		count, err := taskService.PauseTasks(c, scope)
+		if err != nil {
+			response.FailWithMessage("暂停任务失败: "+err.Error(), c)
+			return
+		}
+		response.OkWithDetailed(TaskPauseResponse{Count: count}, "暂停任务成功", c)
+		return
+	}
+
+	count, err := taskService.ResumeTasks(c, scope)
+	if err != nil {
+		response.FailWithMessage("恢复任务失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(TaskPauseResponse{Count: count}, "恢复任务成功", c)
+}
@@ -0,0 +1,35 @@
+package task
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task/service"
+)
+
+// GetAccountQuota 获取账号的任务配额
+// @Summary 获取账号的任务配额
+// @Description 获取账号在滚动窗口内各类任务的配额、已占用数和剩余数，配额用完时返回新任务最早的执行时间
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param account_id path uint true "账号ID"
+// @Success 200 {object} response.Response{data=[]service.TaskQuotaUsage} "获取成功"
+// @Router /api/v1/accounts/{account_id}/quota [get]
+func (ctrl *TaskController) GetAccountQuota(c *gin.Context) {
+	accountID, err := strconv.ParseUint(c.Param("account_id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage("无效的账号ID", c)
+		return
+	}
+
+	usages, err := service.GetAccountQuotas(c, uint(accountID))
+	if err != nil {
+		response.FailWithMessage("获取账号配额失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithData(usages, c)
+}
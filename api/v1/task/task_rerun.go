@@ -0,0 +1,99 @@
+package task
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task"
+	"tg_manager_api/services/task/service"
+)
+
+// RerunTaskRequest 重新执行任务请求
+type RerunTaskRequest struct {
+	Params map[string]interface{} `json:"params"` // 覆盖原任务的参数，值为null时删除该参数，可选
+}
+
+// BulkRerunRequest 批量重新执行任务请求
+type BulkRerunRequest struct {
+	Statuses       []string               `json:"statuses"`         // 任务状态，只能为failed或timeout，默认两者
+	TaskType       string                 `json:"task_type"`        // 任务类型
+	AccountID      uint                   `json:"account_id"`       // 账号ID
+	AccountGroupID uint                   `json:"account_group_id"` // 账号分组ID
+	WorkerID       string                 `json:"worker_id"`        // 最后执行的工作节点ID
+	CompletedFrom  *time.Time             `json:"completed_from"`   // 完成时间下限
+	CompletedTo    *time.Time             `json:"completed_to"`     // 完成时间上限
+	Params         map[string]interface{} `json:"params"`           // 覆盖原任务的参数，值为null时删除该参数，可选
+}
+
+// RerunTask 重新执行任务
+// @Summary 重新执行任务
+// @Description 以已结束任务的类型、账号、参数、优先级和超时时间创建新任务，新任务的rerun_of为原任务ID
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param id path string true "原任务ID"
+// @Param data body RerunTaskRequest false "参数覆盖"
+// @Success 200 {object} response.Response{data=model.Task} "创建成功"
+// @Router /api/v1/tasks/{id}/rerun [post]
+func (ctrl *TaskController) RerunTask(c *gin.Context) {
+	var req RerunTaskRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.FailWithMessage("参数错误: "+err.Error(), c)
+			return
+		}
+	}
+
+	taskService := task.GetTaskServiceFromContext(c)
+	newTask, err := taskService.RerunTask(c, c.Param("id"), req.Params)
+	if err != nil {
+		response.FailWithMessage("重新执行任务失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithDetailed(newTask, "创建成功", c)
+}
+
+// BulkRerunTasks 批量重新执行失败任务
+// @Summary 批量重新执行失败任务
+// @Description 重新执行符合条件的失败或超时任务，至少需要指定一个筛选条件，已重新执行过的任务会被跳过，单次最多500个
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param data body BulkRerunRequest true "筛选条件和参数覆盖"
+// @Success 200 {object} response.Response{data=service.TaskRerunResult} "执行成功"
+// @Router /api/v1/tasks/rerun [post]
+func (ctrl *TaskController) BulkRerunTasks(c *gin.Context) {
+	var req BulkRerunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage("参数错误: "+err.Error(), c)
+		return
+	}
+	// 避免误操作重新执行所有失败任务
+	if req.TaskType == "" && req.AccountID == 0 && req.AccountGroupID == 0 && req.WorkerID == "" &&
+		req.CompletedFrom == nil && req.CompletedTo == nil {
+		response.FailWithMessage("参数错误: 至少需要指定一个筛选条件", c)
+		return
+	}
+
+	query := &service.TaskQuery{
+		Statuses:       req.Statuses,
+		TaskType:       req.TaskType,
+		AccountID:      req.AccountID,
+		AccountGroupID: req.AccountGroupID,
+		WorkerID:       req.WorkerID,
+		CompletedFrom:  req.CompletedFrom,
+		CompletedTo:    req.CompletedTo,
+	}
+
+	taskService := task.GetTaskServiceFromContext(c)
+	result, err := taskService.RerunTasks(c, query, req.Params)
+	if err != nil {
+		response.FailWithMessage("批量重新执行任务失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithDetailed(result, "执行成功", c)
+}
@@ -0,0 +1,241 @@
+package task
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model"
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task"
+	"tg_manager_api/services/task/service"
+	"tg_manager_api/utils"
+)
+
+// CreateTaskScheduleRequest 创建周期计划请求
+type CreateTaskScheduleRequest struct {
+	Name         string                 `json:"name" binding:"required"`       // 计划名称
+	TaskType     string                 `json:"task_type" binding:"required"`  // 任务类型
+	AccountID    uint                   `json:"account_id" binding:"required"` // 关联的账号ID
+	Params       map[string]interface{} `json:"params"`                        // 任务参数，JSON格式
+	Priority     int                    `json:"priority"`                      // 任务优先级
+	TimeoutSec   int                    `json:"timeout_sec"`                   // 任务超时时间(秒)
+	RequiredTags []string               `json:"required_tags"`                 // 任务所需的工作节点标签
+	CronExpr     string                 `json:"cron_expr" binding:"required"`  // Cron表达式，如 "0 9 * * *"
+	Timezone     string                 `json:"timezone"`                      // 时区，默认Asia/Shanghai
+	Enabled      *bool                  `json:"enabled"`                       // 是否启用，默认启用
+}
+
+// UpdateTaskScheduleRequest 更新周期计划请求
+type UpdateTaskScheduleRequest struct {
+	Name         string                 `json:"name"`          // 计划名称
+	Params       map[string]interface{} `json:"params"`        // 任务参数，JSON格式
+	Priority     *int                   `json:"priority"`      // 任务优先级
+	TimeoutSec   *int                   `json:"timeout_sec"`   // 任务超时时间(秒)
+	RequiredTags []string               `json:"required_tags"` // 任务所需的工作节点标签，传入空数组表示清除
+	CronExpr     string                 `json:"cron_expr"`     // Cron表达式
+	Timezone     string                 `json:"timezone"`      // 时区
+	Enabled      *bool                  `json:"enabled"`       // 是否启用
+}
+
+// TaskScheduleController 周期任务计划控制器
+type TaskScheduleController struct{}
+
+// CreateSchedule 创建周期计划
+// @Summary 创建周期计划
+// @Description 创建按Cron表达式定时生成任务的周期计划
+// @Tags TaskSchedule
+// @Accept json
+// @Produce json
+// @Param data body CreateTaskScheduleRequest true "周期计划数据"
+// @Success 200 {object} response.Response{data=model.TaskSchedule} "创建成功"
+// @Router /api/v1/task-schedules [post]
+func (ctrl *TaskScheduleController) CreateSchedule(c *gin.Context) {
+	var req CreateTaskScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage("参数错误: "+err.Error(), c)
+		return
+	}
+
+	schedule := &model.TaskSchedule{
+		Name:         req.Name,
+		TaskType:     req.TaskType,
+		AccountID:    req.AccountID,
+		Params:       req.Params,
+		Priority:     req.Priority,
+		TimeoutSec:   req.TimeoutSec,
+		RequiredTags: service.JoinTags(req.RequiredTags),
+		CronExpr:     req.CronExpr,
+		Timezone:     req.Timezone,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+	}
+
+	// 获取周期计划服务
+	scheduleService := task.GetTaskScheduleServiceFromContext(c)
+
+	// 创建周期计划
+	schedule, err := scheduleService.CreateSchedule(c, schedule)
+	if err != nil {
+		failWithTaskError("创建周期计划失败", err, c)
+		return
+	}
+
+	response.OkWithData(schedule, c)
+}
+
+// GetScheduleList 获取周期计划列表
+// @Summary 获取周期计划列表
+// @Description 分页获取周期计划列表
+// @Tags TaskSchedule
+// @Accept json
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=response.PageResult{list=[]model.TaskSchedule}} "获取成功"
+// @Router /api/v1/task-schedules [get]
+func (ctrl *TaskScheduleController) GetScheduleList(c *gin.Context) {
+	// 获取分页参数
+	page, pageSize := utils.GetPage(c)
+
+	// 获取周期计划服务
+	scheduleService := task.GetTaskScheduleServiceFromContext(c)
+
+	// 获取周期计划列表
+	schedules, total, err := scheduleService.GetSchedules(c, page, pageSize)
+	if err != nil {
+		response.FailWithMessage("获取周期计划列表失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithDetailed(response.PageResult{
+		List:     schedules,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, "获取成功", c)
+}
+
+// GetScheduleDetail 获取周期计划详情
+// @Summary 获取周期计划详情
+// @Description 根据ID获取周期计划详情
+// @Tags TaskSchedule
+// @Accept json
+// @Produce json
+// @Param id path int true "周期计划ID"
+// @Success 200 {object} response.Response{data=model.TaskSchedule} "获取成功"
+// @Router /api/v1/task-schedules/{id} [get]
+func (ctrl *TaskScheduleController) GetScheduleDetail(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage("无效的周期计划ID", c)
+		return
+	}
+
+	// 获取周期计划服务
+	scheduleService := task.GetTaskScheduleServiceFromContext(c)
+
+	// 获取周期计划详情
+	schedule, err := scheduleService.GetSchedule(c, uint(id))
+	if err != nil {
+		response.FailWithMessage("获取周期计划详情失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithData(schedule, c)
+}
+
+// UpdateSchedule 更新周期计划
+// @Summary 更新周期计划
+// @Description 更新周期计划的Cron表达式、参数或启用状态
+// @Tags TaskSchedule
+// @Accept json
+// @Produce json
+// @Param id path int true "周期计划ID"
+// @Param data body UpdateTaskScheduleRequest true "更新的周期计划数据"
+// @Success 200 {object} response.Response{data=model.TaskSchedule} "更新成功"
+// @Router /api/v1/task-schedules/{id} [put]
+func (ctrl *TaskScheduleController) UpdateSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage("无效的周期计划ID", c)
+		return
+	}
+
+	var req UpdateTaskScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage("参数错误: "+err.Error(), c)
+		return
+	}
+
+	// 获取周期计划服务
+	scheduleService := task.GetTaskScheduleServiceFromContext(c)
+
+	// 首先获取周期计划
+	schedule, err := scheduleService.GetSchedule(c, uint(id))
+	if err != nil {
+		response.FailWithMessage("获取周期计划详情失败: "+err.Error(), c)
+		return
+	}
+
+	// 更新字段
+	if req.Name != "" {
+		schedule.Name = req.Name
+	}
+	if req.Params != nil {
+		schedule.Params = req.Params
+	}
+	if req.Priority != nil {
+		schedule.Priority = *req.Priority
+	}
+	if req.TimeoutSec != nil && *req.TimeoutSec > 0 {
+		schedule.TimeoutSec = *req.TimeoutSec
+	}
+	if req.RequiredTags != nil {
+		schedule.RequiredTags = service.JoinTags(req.RequiredTags)
+	}
+	if req.CronExpr != "" {
+		schedule.CronExpr = req.CronExpr
+	}
+	if req.Timezone != "" {
+		schedule.Timezone = req.Timezone
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+
+	// 更新周期计划
+	if err := scheduleService.UpdateSchedule(c, schedule); err != nil {
+		failWithTaskError("更新周期计划失败", err, c)
+		return
+	}
+
+	response.OkWithData(schedule, c)
+}
+
+// DeleteSchedule 删除周期计划
+// @Summary 删除周期计划
+// @Description 删除指定的周期计划，已生成的任务不受影响
+// @Tags TaskSchedule
+// @Accept json
+// @Produce json
+// @Param id path int true "周期计划ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Router /api/v1/task-schedules/{id} [delete]
+func (ctrl *TaskScheduleController) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage("无效的周期计划ID", c)
+		return
+	}
+
+	// 获取周期计划服务
+	scheduleService := task.GetTaskScheduleServiceFromContext(c)
+
+	// 删除周期计划
+	if err := scheduleService.DeleteSchedule(c, uint(id)); err != nil {
+		response.FailWithMessage("删除周期计划失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithMessage("删除周期计划成功", c)
+}
@@ -0,0 +1,96 @@
+package task
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task/scheduler"
+)
+
+// SchedulerController 任务调度器管理控制器
+type SchedulerController struct{}
+
+// GetLeader 获取调度器领导者
+// @Summary 获取调度器领导者
+// @Description 多实例部署时仅领导者实例运行任务调度，返回当前领导者和响应请求的实例标识
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=scheduler.LeaderInfo} "获取成功"
+// @Router /api/v1/admin/scheduler/leader [get]
+func (ctrl *SchedulerController) GetLeader(c *gin.Context) {
+	leader, err := scheduler.GetLeader(c)
+	if err != nil {
+		response.FailWithMessage("获取调度器领导者失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithData(leader, c)
+}
+
+// PauseDispatchRequest 暂停调度请求
+type PauseDispatchRequest struct {
+	Reason string `json:"reason"` // 暂停原因
+}
+
+// GetDispatchPause 获取全局调度暂停状态
+// @Summary 获取全局调度暂停状态
+// @Description 获取所有实例共享的任务调度暂停状态
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=scheduler.DispatchPause} "获取成功"
+// @Router /api/v1/admin/scheduler/pause [get]
+func (ctrl *SchedulerController) GetDispatchPause(c *gin.Context) {
+	pause, err := scheduler.GetDispatchPause(c)
+	if err != nil {
+		response.FailWithMessage("获取调度暂停状态失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithData(pause, c)
+}
+
+// PauseDispatch 暂停全局调度
+// @Summary 暂停全局调度
+// @Description 立即停止所有实例分配任务，已分配和执行中的任务不受影响，待处理任务保持原有队列位置
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Param data body PauseDispatchRequest false "暂停原因"
+// @Success 200 {object} response.Response{data=scheduler.DispatchPause} "暂停成功"
+// @Router /api/v1/admin/scheduler/pause [post]
+func (ctrl *SchedulerController) PauseDispatch(c *gin.Context) {
+	var req PauseDispatchRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.FailWithMessage("参数错误: "+err.Error(), c)
+			return
+		}
+	}
+
+	pause, err := scheduler.PauseDispatch(c, req.Reason)
+	if err != nil {
+		response.FailWithMessage("暂停调度失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithDetailed(pause, "暂停调度成功", c)
+}
+
+// ResumeDispatch 恢复全局调度
+// @Summary 恢复全局调度
+// @Description 恢复所有实例的任务分配并立即触发一次调度
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response "恢复成功"
+// @Router /api/v1/admin/scheduler/resume [post]
+func (ctrl *SchedulerController) ResumeDispatch(c *gin.Context) {
+	if err := scheduler.ResumeDispatch(c); err != nil {
+		response.FailWithMessage("恢复调度失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithMessage("恢复调度成功", c)
+}
@@ -0,0 +1,63 @@
+package task
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task/service"
+)
+
+// TaskStatsRequest 任务耗时统计请求
+type TaskStatsRequest struct {
+	GroupBy   string     `form:"group_by"`                                     // 分组方式: task_type, worker, bucket，默认task_type
+	From      *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"` // 完成时间下限，默认结束时间前24小时
+	To        *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`   // 完成时间上限，默认当前时间
+	BucketSec int        `form:"bucket_sec"`                                   // 按时间分段时每段的秒数，默认3600
+	TaskType  string     `form:"task_type"`                                    // 任务类型
+	WorkerID  string     `form:"worker_id"`                                    // 工作节点ID
+}
+
+// GetTaskSLAStats 获取任务排队和执行耗时统计
+// @Summary 获取任务排队和执行耗时统计
+// @Description 按任务类型、工作节点或时间分段统计排队耗时(入队到分配)和执行耗时(开始到完成)的p50/p95/p99，单位毫秒。记录数超过采样上限时分位数按最近的记录计算，并返回truncated
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param group_by query string false "分组方式: task_type, worker, bucket"
+// @Param from query string false "完成时间下限(RFC3339)"
+// @Param to query string false "完成时间上限(RFC3339)"
+// @Param bucket_sec query int false "时间分段秒数，时间范围最多分为1000段" default(3600)
+// @Param task_type query string false "任务类型"
+// @Param worker_id query string false "工作节点ID"
+// @Success 200 {object} response.Response{data=service.TaskStatsResult} "获取成功"
+// @Router /api/v1/task-stats/sla [get]
+func (ctrl *TaskController) GetTaskSLAStats(c *gin.Context) {
+	var req TaskStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage("参数错误: "+err.Error(), c)
+		return
+	}
+
+	query := &service.TaskStatsQuery{
+		GroupBy:   req.GroupBy,
+		BucketSec: req.BucketSec,
+		TaskType:  req.TaskType,
+		WorkerID:  req.WorkerID,
+	}
+	if req.From != nil {
+		query.From = *req.From
+	}
+	if req.To != nil {
+		query.To = *req.To
+	}
+
+	stats, err := service.GetTaskSLAStats(c, query)
+	if err != nil {
+		response.FailWithMessage("获取任务耗时统计失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithData(stats, c)
+}
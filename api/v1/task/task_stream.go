@@ -0,0 +1,125 @@
+package task
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task/service"
+)
+
+// SSE心跳间隔，避免代理因连接空闲而断开
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamTask 订阅任务状态和进度
+// @Summary 订阅任务状态和进度
+// @Description 通过Server-Sent Events推送任务的状态和进度变化，连接建立后先推送一次当前状态，任务结束后关闭连接
+// @Tags Task
+// @Produce text/event-stream
+// @Param id path string true "任务ID"
+// @Success 200 {object} service.TaskEvent "task事件"
+// @Failure 503 {object} response.Response "未配置Redis，事件推送不可用"
+// @Router /api/v1/tasks/{id}/stream [get]
+func (ctrl *TaskController) StreamTask(c *gin.Context) {
+	// 获取任务ID
+	taskID := c.Param("id")
+
+	// 先订阅再读取当前状态，避免丢失两者之间发生的变化
+	events, err := service.SubscribeTaskEvents(c.Request.Context(), func(event *service.TaskEvent) bool {
+		return event.TaskID == taskID
+	})
+	if err != nil {
+		failSubscribe(err, c)
+		return
+	}
+
+	var current model.Task
+	if err := global.DB.Where("task_id = ?", taskID).First(&current).Error; err != nil {
+		response.FailWithMessage("获取任务详情失败: "+err.Error(), c)
+		return
+	}
+
+	snapshot := service.NewTaskEvent(&current, current.Status)
+	streamTaskEvents(c, snapshot, events, true)
+}
+
+// StreamAccountTasks 订阅账号下所有任务的状态和进度
+// @Summary 订阅账号下所有任务的状态和进度
+// @Description 通过Server-Sent Events推送指定账号下所有任务的状态和进度变化
+// @Tags Task
+// @Produce text/event-stream
+// @Param account_id path uint true "账号ID"
+// @Success 200 {object} service.TaskEvent "task事件"
+// @Failure 503 {object} response.Response "未配置Redis，事件推送不可用"
+// @Router /api/v1/accounts/{account_id}/tasks/stream [get]
+func (ctrl *TaskController) StreamAccountTasks(c *gin.Context) {
+	// 获取账号ID
+	accountID, err := strconv.ParseUint(c.Param("account_id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage("无效的账号ID", c)
+		return
+	}
+
+	events, err := service.SubscribeTaskEvents(c.Request.Context(), func(event *service.TaskEvent) bool {
+		return event.AccountID == uint(accountID)
+	})
+	if err != nil {
+		failSubscribe(err, c)
+		return
+	}
+
+	streamTaskEvents(c, nil, events, false)
+}
+
+// failSubscribe 订阅任务事件失败时的响应，未配置Redis时返回503
+func failSubscribe(err error, c *gin.Context) {
+	if err == global.ErrorEventsUnavailable {
+		c.JSON(http.StatusServiceUnavailable, response.Response{
+			Code: response.ERROR,
+			Data: map[string]interface{}{},
+			Msg:  "任务事件推送不可用: " + err.Error(),
+		})
+		return
+	}
+	response.FailWithMessage("订阅任务事件失败: "+err.Error(), c)
+}
+
+// streamTaskEvents 将任务事件以SSE格式写给客户端
+// closeOnFinish为true时在收到终态事件后结束推送
+func streamTaskEvents(c *gin.Context, snapshot *service.TaskEvent, events <-chan *service.TaskEvent, closeOnFinish bool) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	if snapshot != nil {
+		c.SSEvent("task", snapshot)
+		c.Writer.Flush()
+		if closeOnFinish && service.IsFinishedStatus(snapshot.Status) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("task", event)
+			return !(closeOnFinish && service.IsFinishedStatus(event.Status))
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
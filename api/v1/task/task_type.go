@@ -0,0 +1,35 @@
+package task
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task/service"
+)
+
+// TaskTypeController 任务类型控制器
+type TaskTypeController struct{}
+
+// GetTaskTypes 获取任务类型列表
+// @Summary 获取任务类型列表
+// @Description 获取所有已注册的任务类型及其参数Schema、默认超时时间、默认优先级和所需的工作节点能力
+// @Tags TaskType
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]service.TaskTypeDefinition} "获取成功"
+// @Router /api/v1/task-types [get]
+func (ctrl *TaskTypeController) GetTaskTypes(c *gin.Context) {
+	response.OkWithData(service.ListTaskTypes(), c)
+}
+
+// failWithTaskError 返回任务创建失败的响应，参数校验错误会附带字段级错误
+func failWithTaskError(message string, err error, c *gin.Context) {
+	var paramsErr *service.TaskParamsError
+	if errors.As(err, &paramsErr) {
+		response.FailWithDetailed(paramsErr.Fields, message+": "+err.Error(), c)
+		return
+	}
+	response.FailWithMessage(message+": "+err.Error(), c)
+}
@@ -0,0 +1,102 @@
+package task
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"tg_manager_api/model/response"
+	"tg_manager_api/services/task"
+	"tg_manager_api/services/task/service"
+)
+
+// WorkflowStepRequest 工作流步骤
+type WorkflowStepRequest struct {
+	Key          string                 `json:"key" binding:"required"`        // 步骤标识，在工作流内唯一
+	TaskType     string                 `json:"task_type" binding:"required"`  // 任务类型
+	AccountID    uint                   `json:"account_id" binding:"required"` // 关联的账号ID
+	Params       map[string]interface{} `json:"params"`                        // 任务参数，JSON格式
+	Priority     int                    `json:"priority"`                      // 优先级
+	TimeoutSec   int                    `json:"timeout_sec"`                   // 超时时间(秒)
+	RequiredTags []string               `json:"required_tags"`                 // 所需的工作节点标签
+	DependsOn    []string               `json:"depends_on"`                    // 依赖的步骤标识
+	WaitSec      int                    `json:"wait_sec"`                      // 依赖全部完成后再等待的时间(秒)
+}
+
+// CreateWorkflowRequest 创建工作流请求
+type CreateWorkflowRequest struct {
+	Name          string                `json:"name" binding:"required"`             // 工作流名称
+	FailurePolicy string                `json:"failure_policy"`                      // 失败策略: cancel(默认), skip
+	Steps         []WorkflowStepRequest `json:"steps" binding:"required,min=1,dive"` // 工作流步骤
+}
+
+// TaskWorkflowController 任务工作流控制器
+type TaskWorkflowController struct{}
+
+// CreateWorkflow 创建任务工作流
+// @Summary 创建任务工作流
+// @Description 提交一组带有依赖关系的任务，上游任务全部完成后才会调度下游任务
+// @Tags TaskWorkflow
+// @Accept json
+// @Produce json
+// @Param data body CreateWorkflowRequest true "工作流数据"
+// @Success 200 {object} response.Response{data=model.TaskWorkflow} "创建成功"
+// @Router /api/v1/task-workflows [post]
+func (ctrl *TaskWorkflowController) CreateWorkflow(c *gin.Context) {
+	var req CreateWorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage("参数错误: "+err.Error(), c)
+		return
+	}
+
+	steps := make([]service.WorkflowStepSpec, 0, len(req.Steps))
+	for _, step := range req.Steps {
+		steps = append(steps, service.WorkflowStepSpec{
+			Key:          step.Key,
+			TaskType:     step.TaskType,
+			AccountID:    step.AccountID,
+			Params:       step.Params,
+			Priority:     step.Priority,
+			TimeoutSec:   step.TimeoutSec,
+			RequiredTags: step.RequiredTags,
+			DependsOn:    step.DependsOn,
+			WaitSec:      step.WaitSec,
+		})
+	}
+
+	// 获取任务工作流服务
+	workflowService := task.GetTaskWorkflowServiceFromContext(c)
+
+	// 创建工作流
+	workflow, err := workflowService.CreateWorkflow(c, req.Name, req.FailurePolicy, steps)
+	if err != nil {
+		failWithTaskError("创建任务工作流失败", err, c)
+		return
+	}
+
+	response.OkWithData(workflow, c)
+}
+
+// GetWorkflowStatus 获取工作流汇总状态
+// @Summary 获取工作流汇总状态
+// @Description 获取工作流中所有任务、依赖关系及汇总状态
+// @Tags TaskWorkflow
+// @Accept json
+// @Produce json
+// @Param id path string true "工作流ID"
+// @Success 200 {object} response.Response{data=service.WorkflowStatus} "获取成功"
+// @Router /api/v1/task-workflows/{id} [get]
+func (ctrl *TaskWorkflowController) GetWorkflowStatus(c *gin.Context) {
+	// 获取工作流ID
+	workflowID := c.Param("id")
+
+	// 获取任务工作流服务
+	workflowService := task.GetTaskWorkflowServiceFromContext(c)
+
+	// 获取工作流状态
+	status, err := workflowService.GetWorkflowStatus(c, workflowID)
+	if err != nil {
+		response.FailWithMessage("获取任务工作流状态失败: "+err.Error(), c)
+		return
+	}
+
+	response.OkWithData(status, c)
+}
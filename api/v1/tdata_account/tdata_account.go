@@ -336,6 +336,10 @@ func UpdateTdataAccount(c *gin.Context) {
 	if req.AccountLevel > 0 && req.AccountLevel <= 3 {
 		tdataAccount.AccountLevel = req.AccountLevel
 	}
+	
+	if req.MaxConcurrentTasks != nil && *req.MaxConcurrentTasks >= 0 {
+		tdataAccount.MaxConcurrentTasks = *req.MaxConcurrentTasks
+	}
 
 	// 调用服务层更新账号
 	if err := accountService.UpdateAccount(context.Background(), tdataAccount); err != nil {
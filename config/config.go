@@ -9,6 +9,8 @@ type Configuration struct {
 	Etcd     Etcd     `mapstructure:"etcd" json:"etcd" toml:"etcd"`
 	Nacos    Nacos    `mapstructure:"nacos" json:"nacos" toml:"nacos"`
 	Zap      Zap      `mapstructure:"zap" json:"zap" toml:"zap"`
+	Task     Task     `mapstructure:"task" json:"task" toml:"task"`
+	Worker   Worker   `mapstructure:"worker" json:"worker" toml:"worker"`
 }
 
 // System 系统基础配置
@@ -91,3 +93,45 @@ type Nacos struct {
 	Username      string `mapstructure:"username" json:"username" toml:"username"`                 // 用户名
 	Password      string `mapstructure:"password" json:"password" toml:"password"`                 // 密码
 }
+
+// Task 任务服务配置
+type Task struct {
+	IdempotencyTTL     int  `mapstructure:"idempotency-ttl" json:"idempotencyTTL" toml:"idempotency-ttl"`             // 幂等键保留时间(秒)
+	AccountConcurrency int  `mapstructure:"account-concurrency" json:"accountConcurrency" toml:"account-concurrency"` // 每个账号同时执行的任务数，账号可单独设置
+	LeaderTTL          int  `mapstructure:"leader-ttl" json:"leaderTTL" toml:"leader-ttl"`                            // 调度器领导者租约时长(秒)，领导者失联超过该时间后其他实例接管
+	RequireCapability  bool `mapstructure:"require-capability" json:"requireCapability" toml:"require-capability"`    // 工作节点须以标签声明任务类型要求的能力才能执行该类任务，所有节点都已配置标签后再开启
+
+	Scheduling TaskScheduling `mapstructure:"scheduling" json:"scheduling" toml:"scheduling"` // 调度策略
+	Quota      TaskQuota      `mapstructure:"quota" json:"quota" toml:"quota"`                // 账号执行任务的配额
+	Retention  TaskRetention  `mapstructure:"retention" json:"retention" toml:"retention"`    // 已结束任务的保留策略
+}
+
+// Worker 工作节点配置
+type Worker struct {
+	HeartbeatInterval int `mapstructure:"heartbeat-interval" json:"heartbeatInterval" toml:"heartbeat-interval"` // 工作节点心跳间隔(秒)，默认30
+	MissedHeartbeats  int `mapstructure:"missed-heartbeats" json:"missedHeartbeats" toml:"missed-heartbeats"`    // 连续错过该次数的心跳后判定节点离线，默认3
+}
+
+// TaskScheduling 任务调度策略配置
+type TaskScheduling struct {
+	Policy        string         `mapstructure:"policy" json:"policy" toml:"policy"`                        // 调度策略: priority, fair
+	FairKey       string         `mapstructure:"fair-key" json:"fairKey" toml:"fair-key"`                   // 公平调度的分组依据: account_group, tenant
+	Weights       map[string]int `mapstructure:"weights" json:"weights" toml:"weights"`                     // 各账号分组或租户的权重，key为分组ID或租户ID，未配置的权重为1
+	AgingInterval int            `mapstructure:"aging-interval" json:"agingInterval" toml:"aging-interval"` // 优先级老化间隔(秒)，每等待该时长有效优先级加1，0表示不老化
+}
+
+// TaskRetention 已结束任务的保留策略，超过保留期的任务移入归档表
+type TaskRetention struct {
+	Days      int      `mapstructure:"days" json:"days" toml:"days"`                   // 任务结束后在线保留的天数，0表示不归档
+	Statuses  []string `mapstructure:"statuses" json:"statuses" toml:"statuses"`       // 归档的任务状态，默认所有已结束状态
+	BatchSize int      `mapstructure:"batch-size" json:"batchSize" toml:"batch-size"` // 每批归档的任务数，默认500
+	Interval  int      `mapstructure:"interval" json:"interval" toml:"interval"`       // 归档任务的运行间隔(秒)，默认3600
+}
+
+// TaskQuota 账号在滚动窗口内各类任务的配额，key为任务类型，不区分大小写，未配置或为0表示不限制
+type TaskQuota struct {
+	Window int                       `mapstructure:"window" json:"window" toml:"window"` // 滚动窗口时长(秒)，默认86400
+	Limits map[string]int            `mapstructure:"limits" json:"limits" toml:"limits"` // 各任务类型的默认配额
+	Levels map[string]map[string]int `mapstructure:"levels" json:"levels" toml:"levels"` // 按账号等级覆盖，key为账号等级
+	Groups map[string]map[string]int `mapstructure:"groups" json:"groups" toml:"groups"` // 按账号分组覆盖，key为分组ID，优先于账号等级
+}
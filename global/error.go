@@ -10,8 +10,20 @@ var (
 	ErrorNoAvailableWorker  = errors.New("no available worker")
 	ErrorTaskAlreadyExist   = errors.New("task already exists")
 	ErrorInvalidTaskStatus  = errors.New("invalid task status")
+	ErrorTaskStatusConflict = errors.New("task status changed concurrently")
 	ErrorInvalidTaskType    = errors.New("invalid task type")
+	ErrorInvalidTaskParams  = errors.New("invalid task params")
 	ErrorQueuePublishFailed = errors.New("failed to publish message to queue")
 	ErrorJsonMarshalFailed  = errors.New("failed to marshal JSON")
 	ErrorJsonUnmarshalFailed = errors.New("failed to unmarshal JSON")
+	ErrorScheduleNotFound   = errors.New("task schedule not found")
+	ErrorWorkflowNotFound   = errors.New("task workflow not found")
+	ErrorInvalidWorkflow    = errors.New("invalid task workflow")
+	ErrorBatchNotFound      = errors.New("task batch not found")
+	ErrorNoEligibleAccount  = errors.New("no eligible account")
+	ErrorInvalidCursor      = errors.New("invalid pagination cursor")
+	ErrorInvalidTaskScope   = errors.New("account or account group is required")
+	ErrorEventsUnavailable  = errors.New("task events require redis")
+	ErrorIdempotencyUnavailable = errors.New("idempotency keys require redis")
+	ErrorTaskAlreadyRerun   = errors.New("task already rerun")
 )
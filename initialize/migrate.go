@@ -11,6 +11,21 @@ func initMigrate() {
 		// System models
 		&model.Account{},
 		&model.AccountGroup{},
+		
+		// Task models
+		&model.Task{},
+		&model.TaskAssignment{},
+		&model.TaskRecord{},
+		&model.TaskSchedule{},
+		&model.TaskWorkflow{},
+		&model.TaskDependency{},
+		&model.TaskBatch{},
+		&model.TaskLog{},
+		&model.TaskStatusHistory{},
+		&model.TaskOutbox{},
+		&model.TaskArchive{},
+		&model.Worker{},
+		&model.AccountWorkerAffinity{},
 	)
 	
 	if err != nil {
@@ -24,9 +24,11 @@ func InitTaskScheduler() *scheduler.TaskScheduler {
 	// 获取服务实例
 	taskSvc := taskService.NewTaskService()
 	workerSvc := workerService.NewWorkerService()
+	scheduleSvc := taskService.NewTaskScheduleService()
+	workflowSvc := taskService.NewTaskWorkflowService()
 	
 	// 创建任务调度器
-	taskScheduler := scheduler.NewTaskScheduler(taskSvc, workerSvc, rabbitMQService)
+	taskScheduler := scheduler.NewTaskScheduler(taskSvc, workerSvc, scheduleSvc, workflowSvc, rabbitMQService)
 	
 	// 启动调度器
 	err = taskScheduler.Start()
@@ -18,6 +18,8 @@ type Account struct {
 	CheckResult     string `json:"check_result"`      // 检测结果
 	AccountLevel    int    `json:"account_level"`     // 账号等级：1-普通，2-中级，3-高级
 	CreatedByUserID uint   `json:"created_by_user_id"` // 创建用户ID
+	MaxConcurrentTasks int `json:"max_concurrent_tasks"` // 同时执行的任务数上限，0表示使用全局配置
+	TasksPaused     bool   `json:"tasks_paused"`      // 是否暂停调度该账号的任务，暂停期间新任务创建为暂停状态
 	
 	// 外键关系
 	AccountGroup AccountGroup `json:"account_group" gorm:"foreignKey:AccountGroupID"` // 关联的账号分组
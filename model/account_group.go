@@ -6,6 +6,7 @@ type AccountGroup struct {
 	Name        string `json:"name"`        // 分组名称
 	Description string `json:"description"` // 分组描述
 	Status      string `json:"status"`      // 状态: ACTIVE(活跃), INACTIVE(停用)
+	TasksPaused bool   `json:"tasks_paused"` // 是否暂停调度分组内账号的任务，暂停期间新任务创建为暂停状态
 	
 	// 关联关系
 	Accounts []Account `json:"accounts" gorm:"foreignKey:AccountGroupID"` // 分组下的账号列表
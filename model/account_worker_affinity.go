@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// AccountWorkerAffinity 账号与工作节点的亲和关系
+// 调度器优先把账号的任务分配给上次执行它的工作节点，避免重复加载tdata和会话
+type AccountWorkerAffinity struct {
+	BaseModel
+	AccountID      uint      `gorm:"uniqueIndex;column:account_id;comment:账号ID" json:"account_id"`     // 关联的账号ID
+	WorkerID       string    `gorm:"index;column:worker_id;comment:工作节点ID" json:"worker_id"`          // 亲和的工作节点ID
+	LastAssignedAt time.Time `gorm:"column:last_assigned_at;comment:最后分配时间" json:"last_assigned_at"` // 最后一次分配到该节点的时间
+}
+
+// TableName 设置表名
+func (AccountWorkerAffinity) TableName() string {
+	return "account_worker_affinities"
+}
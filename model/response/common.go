@@ -21,6 +21,13 @@ type PageResult struct {
 	PageSize int         `json:"page_size"` // 每页大小
 }
 
+// CursorPageResult 游标分页结果
+type CursorPageResult struct {
+	List       interface{} `json:"list"`        // 数据列表
+	NextCursor string      `json:"next_cursor"` // 下一页游标，为空表示没有更多数据
+	PageSize   int         `json:"page_size"`   // 每页大小
+}
+
 const (
 	SUCCESS = 0   // 成功
 	ERROR   = 7   // 失败
@@ -11,15 +11,40 @@ import (
 type Task struct {
 	BaseModel
 	TaskID      string          `gorm:"uniqueIndex;column:task_id;comment:任务ID" json:"task_id"`        // 任务ID
-	TaskType    string          `gorm:"column:task_type;comment:任务类型" json:"task_type"`              // 任务类型: send_message, join_group, add_contact等
+	TaskType    string          `gorm:"index;column:task_type;comment:任务类型" json:"task_type"`        // 任务类型: send_message, join_group, add_contact等
 	AccountID   uint            `gorm:"index;column:account_id;comment:账号ID" json:"account_id"`       // 关联的账号ID
 	Params      TaskParams      `gorm:"type:json;column:params;comment:任务参数" json:"params"`           // 任务参数，JSON格式
-	Status      string          `gorm:"column:status;comment:任务状态" json:"status"`                    // 状态: pending, assigned, processing, completed, failed
-	Priority    int             `gorm:"column:priority;default:0;comment:任务优先级" json:"priority"`      // 优先级，数字越大优先级越高
+	Status      string          `gorm:"index:idx_tasks_status_priority,priority:1;column:status;comment:任务状态" json:"status"` // 状态: blocked, pending, paused, assigned, processing, canceling, completed, failed, canceled, timeout, skipped
+	Priority    int             `gorm:"index:idx_tasks_status_priority,priority:2;column:priority;default:0;comment:任务优先级" json:"priority"` // 优先级，数字越大优先级越高
 	ErrorMessage string         `gorm:"column:error_message;comment:错误信息" json:"error_message"`      // 错误信息
+	Progress    int             `gorm:"column:progress;default:0;comment:执行进度" json:"progress"`        // 执行进度百分比(0-100)，由工作节点上报
+	ProgressMessage string      `gorm:"column:progress_message;comment:进度说明" json:"progress_message"`  // 最近一次进度上报的说明
 	TimeoutSec  int             `gorm:"column:timeout_sec;default:300;comment:超时时间(秒)" json:"timeout_sec"` // 执行超时时间，单位秒
+	RunAt       *time.Time      `gorm:"index;column:run_at;comment:计划执行时间" json:"run_at"`              // 计划执行时间，为空表示立即执行
+	ScheduleID  uint            `gorm:"index;column:schedule_id;comment:周期计划ID" json:"schedule_id"`     // 生成该任务的周期计划ID，0表示手动创建
+	BatchID     string          `gorm:"index;column:batch_id;comment:批次ID" json:"batch_id"`            // 所属批次ID，为空表示单独创建
+	WorkflowID  string          `gorm:"index;column:workflow_id;comment:工作流ID" json:"workflow_id"`      // 所属工作流ID，为空表示独立任务
+	WorkflowStep string         `gorm:"column:workflow_step;comment:工作流步骤" json:"workflow_step"`        // 任务在工作流中的步骤标识
+	WaitSec     int             `gorm:"column:wait_sec;default:0;comment:依赖完成后等待时间(秒)" json:"wait_sec"`  // 上游任务全部完成后再等待的时间，单位秒
+	RerunOf     string          `gorm:"index;column:rerun_of;comment:原任务ID" json:"rerun_of"`          // 重新执行的原任务ID，为空表示不是重新执行创建的
+	BulkRerunOf *string         `gorm:"uniqueIndex;column:bulk_rerun_of;comment:批量重新执行的原任务ID" json:"-"` // 批量重新执行的原任务ID，唯一索引保证每个任务只被批量重新执行一次
+	QueuedAt    *time.Time      `gorm:"column:queued_at;comment:入队时间" json:"queued_at"`              // 本次尝试可被调度的时间，即创建、计划执行和重试时间中最晚者
+	AssignedAt  *time.Time      `gorm:"column:assigned_at;comment:分配时间" json:"assigned_at"`          // 本次尝试分配给工作节点的时间
 	StartedAt   *time.Time      `gorm:"column:started_at;comment:开始时间" json:"started_at"`            // 开始执行时间
-	CompletedAt *time.Time      `gorm:"column:completed_at;comment:完成时间" json:"completed_at"`        // 完成时间
+	CompletedAt *time.Time      `gorm:"index;column:completed_at;comment:完成时间" json:"completed_at"`  // 完成时间
+	
+	// 重试设置
+	MaxAttempts        int        `gorm:"column:max_attempts;default:1;comment:最大尝试次数" json:"max_attempts"`                     // 最大尝试次数，1表示不重试
+	Attempt            int        `gorm:"column:attempt;default:0;comment:已尝试次数" json:"attempt"`                                 // 已尝试次数，每次分配时递增
+	RetryBackoffSec    int        `gorm:"column:retry_backoff_sec;default:30;comment:重试退避基数(秒)" json:"retry_backoff_sec"`         // 重试退避基数，按尝试次数指数递增
+	RetryBackoffMaxSec int        `gorm:"column:retry_backoff_max_sec;default:1800;comment:重试退避上限(秒)" json:"retry_backoff_max_sec"` // 重试退避上限
+	RetryableErrors    string     `gorm:"column:retryable_errors;comment:可重试的错误类型(逗号分隔)" json:"retryable_errors"`                  // 可重试的错误类型，为空表示所有错误均可重试
+	NextRetryAt        *time.Time `gorm:"index;column:next_retry_at;comment:下次重试时间" json:"next_retry_at"`                         // 下次重试时间，到期前不会被调度
+	
+	// 工作节点匹配
+	RequiredTags        string `gorm:"column:required_tags;comment:所需工作节点标签(逗号分隔)" json:"required_tags"`                 // 执行任务所需的工作节点标签，如区域、代理类型，任务类型要求的能力无需重复填写
+	Unschedulable       bool   `gorm:"column:unschedulable;default:false;comment:是否无法调度" json:"unschedulable"`               // 没有在线工作节点具备所需标签时标记，任务保持待处理
+	UnschedulableReason string `gorm:"column:unschedulable_reason;comment:无法调度原因" json:"unschedulable_reason"`              // 无法调度的原因
 	
 	// 外键关系
 	Account    *Account         `json:"account,omitempty" gorm:"foreignKey:AccountID"` // 关联的账号
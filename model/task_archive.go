@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// TaskArchive 已归档的任务
+// 超过保留期的已结束任务连同分配记录、执行记录、状态历史和日志一起以JSON保存，并从在线表中删除
+type TaskArchive struct {
+	BaseModel
+	TaskID      string     `gorm:"uniqueIndex;column:task_id;comment:任务ID" json:"task_id"`   // 任务ID
+	TaskType    string     `gorm:"column:task_type;comment:任务类型" json:"task_type"`           // 任务类型
+	AccountID   uint       `gorm:"index;column:account_id;comment:账号ID" json:"account_id"`   // 关联的账号ID
+	Status      string     `gorm:"column:status;comment:任务状态" json:"status"`                 // 归档时的任务状态
+	CompletedAt *time.Time `gorm:"column:completed_at;comment:完成时间" json:"completed_at"`     // 完成时间
+	ArchivedAt  time.Time  `gorm:"index;column:archived_at;comment:归档时间" json:"archived_at"` // 归档时间
+	Data        string     `gorm:"type:longtext;column:data;comment:归档数据" json:"-"`          // 任务及关联记录的JSON
+}
+
+// TableName 设置表名
+func (TaskArchive) TableName() string {
+	return "task_archives"
+}
@@ -0,0 +1,17 @@
+package model
+
+// TaskBatch 批量任务模型，一次为多个账号创建同类任务
+type TaskBatch struct {
+	BaseModel
+	BatchID        string     `gorm:"uniqueIndex;column:batch_id;comment:批次ID" json:"batch_id"`              // 批次ID
+	TaskType       string     `gorm:"column:task_type;comment:任务类型" json:"task_type"`                        // 任务类型
+	AccountGroupID uint       `gorm:"index;column:account_group_id;comment:账号分组ID" json:"account_group_id"` // 目标账号分组ID，0表示按账号列表创建
+	Params         TaskParams `gorm:"type:json;column:params;comment:任务参数" json:"params"`                     // 任务参数模板，JSON格式
+	TotalCount     int        `gorm:"column:total_count;comment:创建的任务数" json:"total_count"`                 // 实际创建的任务数
+	SkippedCount   int        `gorm:"column:skipped_count;comment:跳过的账号数" json:"skipped_count"`             // 因账号状态不符被跳过的账号数
+}
+
+// TableName 设置表名
+func (TaskBatch) TableName() string {
+	return "task_batches"
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// TaskLog 任务日志模型，包括工作节点上报的执行日志和API记录的生命周期日志
+type TaskLog struct {
+	BaseModel
+	TaskID   string    `gorm:"index:idx_task_logs_task_time;column:task_id;comment:任务ID" json:"task_id"`     // 关联的任务ID
+	WorkerID string    `gorm:"column:worker_id;comment:工作节点ID" json:"worker_id"`                             // 产生日志的工作节点ID，API记录的日志为空
+	Level    string    `gorm:"column:level;comment:日志级别" json:"level"`                                       // 日志级别: debug, info, warn, error
+	Message  string    `gorm:"type:text;column:message;comment:日志内容" json:"message"`                         // 日志内容
+	LoggedAt time.Time `gorm:"index:idx_task_logs_task_time;column:logged_at;comment:日志时间" json:"logged_at"` // 日志产生时间
+}
+
+// TableName 设置表名
+func (TaskLog) TableName() string {
+	return "task_logs"
+}
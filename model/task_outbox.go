@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// TaskOutbox 待发送的任务消息
+// 与任务状态变更在同一事务中写入，由中继发送到RabbitMQ，保证消息至少发送一次
+type TaskOutbox struct {
+	BaseModel
+	TaskID        string     `gorm:"index;column:task_id;comment:任务ID" json:"task_id"`                                               // 关联的任务ID
+	RoutingKey    string     `gorm:"column:routing_key;comment:路由键" json:"routing_key"`                                              // 路由键，任务消息为任务类型
+	Payload       string     `gorm:"type:text;column:payload;comment:消息内容" json:"payload"`                                           // 消息内容(JSON)
+	Status        string     `gorm:"index:idx_task_outbox_status_next;column:status;comment:发送状态" json:"status"`                     // 状态: pending, sent
+	Attempts      int        `gorm:"column:attempts;default:0;comment:发送次数" json:"attempts"`                                         // 已尝试发送的次数
+	NextAttemptAt time.Time  `gorm:"index:idx_task_outbox_status_next;column:next_attempt_at;comment:下次发送时间" json:"next_attempt_at"` // 下次尝试发送的时间
+	LastError     string     `gorm:"type:text;column:last_error;comment:最后错误" json:"last_error"`                                     // 最后一次发送失败的原因
+	SentAt        *time.Time `gorm:"column:sent_at;comment:发送时间" json:"sent_at"`                                                     // 发送成功时间
+}
+
+// TableName 设置表名
+func (TaskOutbox) TableName() string {
+	return "task_outbox"
+}
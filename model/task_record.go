@@ -12,11 +12,16 @@ type TaskRecord struct {
 	BaseModel
 	TaskID       string       `gorm:"index;column:task_id;comment:任务ID" json:"task_id"`            // 关联的任务ID
 	WorkerID     string       `gorm:"index;column:worker_id;comment:工作节点ID" json:"worker_id"`     // 执行任务的工作节点ID
-	Status       string       `gorm:"column:status;comment:执行状态" json:"status"`                   // 状态: processing, completed, failed
+	TaskType     string       `gorm:"index;column:task_type;comment:任务类型" json:"task_type"`        // 任务类型，用于按类型统计耗时
+	Attempt      int          `gorm:"column:attempt;default:1;comment:尝试次数" json:"attempt"`          // 第几次尝试
+	Status       string       `gorm:"column:status;comment:执行状态" json:"status"`                   // 状态: processing, completed, failed, timeout
 	Result       TaskResult   `gorm:"type:json;column:result;comment:执行结果" json:"result"`          // 执行结果，JSON格式
 	ErrorMessage string       `gorm:"column:error_message;comment:错误信息" json:"error_message"`     // 错误信息
+	QueuedAt     *time.Time   `gorm:"column:queued_at;comment:入队时间" json:"queued_at"`             // 本次尝试可被调度的时间
+	AssignedAt   *time.Time   `gorm:"column:assigned_at;comment:分配时间" json:"assigned_at"`         // 分配给工作节点的时间
 	StartedAt    time.Time    `gorm:"column:started_at;comment:开始时间" json:"started_at"`           // 开始执行时间
-	CompletedAt  *time.Time   `gorm:"column:completed_at;comment:完成时间" json:"completed_at"`       // 完成时间
+	CompletedAt  *time.Time   `gorm:"index;column:completed_at;comment:完成时间" json:"completed_at"` // 完成时间
+	WaitTime     *int         `gorm:"column:wait_time;comment:排队耗时(毫秒)" json:"wait_time"`         // 从入队到分配的耗时，单位毫秒，缺少排队时间时为空
 	ExecutionTime int         `gorm:"column:execution_time;comment:执行耗时(毫秒)" json:"execution_time"` // 执行耗时，单位毫秒
 	
 	// 外键关系
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// TaskSchedule 周期任务计划模型
+type TaskSchedule struct {
+	BaseModel
+	Name        string     `gorm:"column:name;comment:计划名称" json:"name"`                                    // 计划名称
+	TaskType    string     `gorm:"column:task_type;comment:任务类型" json:"task_type"`                          // 生成任务的类型
+	AccountID   uint       `gorm:"index;column:account_id;comment:账号ID" json:"account_id"`                   // 生成任务关联的账号ID
+	Params      TaskParams `gorm:"type:json;column:params;comment:任务参数" json:"params"`                       // 生成任务的参数，JSON格式
+	Priority    int        `gorm:"column:priority;default:0;comment:任务优先级" json:"priority"`                  // 生成任务的优先级
+	TimeoutSec  int        `gorm:"column:timeout_sec;default:300;comment:超时时间(秒)" json:"timeout_sec"`        // 生成任务的执行超时时间，单位秒
+	RequiredTags string    `gorm:"column:required_tags;comment:所需工作节点标签(逗号分隔)" json:"required_tags"`        // 生成任务所需的工作节点标签
+	CronExpr    string     `gorm:"column:cron_expr;comment:Cron表达式" json:"cron_expr"`                       // 标准5段Cron表达式，如 "0 9 * * *"
+	Timezone    string     `gorm:"column:timezone;default:Asia/Shanghai;comment:时区" json:"timezone"`        // Cron表达式所在时区
+	Enabled     bool       `gorm:"column:enabled;default:true;comment:是否启用" json:"enabled"`                 // 是否启用
+	NextRunAt   *time.Time `gorm:"index;column:next_run_at;comment:下次执行时间" json:"next_run_at"`              // 下次执行时间
+	LastRunAt   *time.Time `gorm:"column:last_run_at;comment:上次执行时间" json:"last_run_at"`                    // 上次执行时间
+	LastTaskID  string     `gorm:"column:last_task_id;comment:上次生成的任务ID" json:"last_task_id"`               // 上次生成的任务ID
+
+	// 外键关系
+	Account *Account `json:"account,omitempty" gorm:"foreignKey:AccountID"` // 关联的账号
+}
+
+// TableName 设置表名
+func (TaskSchedule) TableName() string {
+	return "task_schedules"
+}
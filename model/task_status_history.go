@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// TaskStatusHistory 任务状态变化历史
+type TaskStatusHistory struct {
+	BaseModel
+	TaskID     string    `gorm:"index:idx_task_status_history_task_time;column:task_id;comment:任务ID" json:"task_id"`       // 关联的任务ID
+	FromStatus string    `gorm:"column:from_status;comment:原状态" json:"from_status"`                                        // 原状态，任务创建时为空
+	ToStatus   string    `gorm:"column:to_status;comment:新状态" json:"to_status"`                                            // 新状态
+	Actor      string    `gorm:"column:actor;comment:操作者" json:"actor"`                                                    // 操作者: user, scheduler, system或工作节点ID
+	Reason     string    `gorm:"type:text;column:reason;comment:原因" json:"reason"`                                         // 状态变化原因
+	ChangedAt  time.Time `gorm:"index:idx_task_status_history_task_time;column:changed_at;comment:变化时间" json:"changed_at"` // 状态变化时间
+}
+
+// TableName 设置表名
+func (TaskStatusHistory) TableName() string {
+	return "task_status_history"
+}
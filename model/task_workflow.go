@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// TaskWorkflow 任务工作流模型，由多个存在依赖关系的任务组成
+type TaskWorkflow struct {
+	BaseModel
+	WorkflowID    string     `gorm:"uniqueIndex;column:workflow_id;comment:工作流ID" json:"workflow_id"`       // 工作流ID
+	Name          string     `gorm:"column:name;comment:工作流名称" json:"name"`                                 // 工作流名称
+	Status        string     `gorm:"column:status;comment:工作流状态" json:"status"`                             // 汇总状态: pending, running, completed, failed, canceled
+	FailurePolicy string     `gorm:"column:failure_policy;default:cancel;comment:失败策略" json:"failure_policy"` // 任务失败时的处理策略: cancel(取消整个工作流), skip(仅跳过下游任务)
+	CompletedAt   *time.Time `gorm:"column:completed_at;comment:完成时间" json:"completed_at"`                  // 所有任务结束的时间
+
+	// 外键关系
+	Tasks []Task `json:"tasks,omitempty" gorm:"foreignKey:WorkflowID;references:WorkflowID"` // 工作流中的任务
+}
+
+// TableName 设置表名
+func (TaskWorkflow) TableName() string {
+	return "task_workflows"
+}
+
+// TaskDependency 任务依赖关系模型，TaskID需等待DependsOnTaskID完成后才能执行
+type TaskDependency struct {
+	BaseModel
+	WorkflowID      string `gorm:"index;column:workflow_id;comment:工作流ID" json:"workflow_id"`                // 所属工作流ID
+	TaskID          string `gorm:"index;column:task_id;comment:任务ID" json:"task_id"`                         // 下游任务ID
+	DependsOnTaskID string `gorm:"index;column:depends_on_task_id;comment:上游任务ID" json:"depends_on_task_id"` // 上游任务ID
+}
+
+// TableName 设置表名
+func (TaskDependency) TableName() string {
+	return "task_dependencies"
+}
@@ -13,10 +13,18 @@ import (
 func InitTaskWorkerRouter(Router *gin.RouterGroup) {
 	// 注册服务中间件
 	Router.Use(taskService.InjectTaskService)
+	Router.Use(taskService.InjectTaskScheduleService)
+	Router.Use(taskService.InjectTaskWorkflowService)
+	Router.Use(taskService.InjectTaskBatchService)
 	Router.Use(workerService.InjectWorkerService)
 	
 	// 实例化控制器
 	taskController := task.TaskController{}
+	taskScheduleController := task.TaskScheduleController{}
+	taskWorkflowController := task.TaskWorkflowController{}
+	taskBatchController := task.TaskBatchController{}
+	taskTypeController := task.TaskTypeController{}
+	schedulerController := task.SchedulerController{}
 	workerController := worker.WorkerController{}
 	
 	// 任务管理路由
@@ -26,11 +34,67 @@ func InitTaskWorkerRouter(Router *gin.RouterGroup) {
 		taskRouter.GET("", taskController.GetTaskList)                         // 获取任务列表
 		taskRouter.GET("/:id", taskController.GetTaskDetail)                   // 获取任务详情
 		taskRouter.POST("/:id/cancel", taskController.CancelTask)              // 取消任务
+		taskRouter.POST("/:id/pause", taskController.PauseTask)                // 暂停任务
+		taskRouter.POST("/:id/resume", taskController.ResumeTask)              // 恢复任务
+		taskRouter.POST("/:id/rerun", taskController.RerunTask)                // 重新执行任务
+		taskRouter.POST("/rerun", taskController.BulkRerunTasks)               // 批量重新执行失败任务
 		taskRouter.GET("/:id/logs", taskController.GetTaskLogs)                // 获取任务日志
+		taskRouter.GET("/:id/stream", taskController.StreamTask)               // 订阅任务状态和进度
+		taskRouter.GET("/:id/timeline", taskController.GetTaskTimeline)        // 获取任务状态时间线
+	}
+	
+	// 任务类型路由
+	Router.GET("/task-types", taskTypeController.GetTaskTypes) // 获取任务类型列表
+	
+	// 归档任务路由
+	Router.GET("/task-archives/:task_id", taskController.GetArchivedTask) // 查询已归档的任务
+	
+	// 任务统计路由
+	Router.GET("/task-stats/sla", taskController.GetTaskSLAStats) // 获取任务排队和执行耗时统计
+	
+	// 任务工作流路由
+	taskWorkflowRouter := Router.Group("task-workflows")
+	{
+		taskWorkflowRouter.POST("", taskWorkflowController.CreateWorkflow)          // 创建任务工作流
+		taskWorkflowRouter.GET("/:id", taskWorkflowController.GetWorkflowStatus)    // 获取工作流汇总状态
+	}
+	
+	// 批量任务路由
+	taskBatchRouter := Router.Group("task-batches")
+	{
+		taskBatchRouter.POST("", taskBatchController.CreateBatch)                   // 批量创建任务
+		taskBatchRouter.GET("/:id", taskBatchController.GetBatchProgress)           // 获取批量任务进度
+	}
+	
+	// 周期任务计划路由
+	taskScheduleRouter := Router.Group("task-schedules")
+	{
+		taskScheduleRouter.POST("", taskScheduleController.CreateSchedule)          // 创建周期计划
+		taskScheduleRouter.GET("", taskScheduleController.GetScheduleList)          // 获取周期计划列表
+		taskScheduleRouter.GET("/:id", taskScheduleController.GetScheduleDetail)    // 获取周期计划详情
+		taskScheduleRouter.PUT("/:id", taskScheduleController.UpdateSchedule)       // 更新周期计划
+		taskScheduleRouter.DELETE("/:id", taskScheduleController.DeleteSchedule)    // 删除周期计划
 	}
 	
 	// 账号关联任务路由
 	Router.GET("/accounts/:account_id/tasks", taskController.GetTasksByAccount) // 获取账号关联的任务
+	Router.GET("/accounts/:account_id/tasks/stream", taskController.StreamAccountTasks) // 订阅账号下所有任务的状态和进度
+	Router.POST("/accounts/:account_id/tasks/pause", taskController.PauseAccountTasks)   // 暂停账号的任务
+	Router.POST("/accounts/:account_id/tasks/resume", taskController.ResumeAccountTasks) // 恢复账号的任务
+	Router.GET("/accounts/:account_id/quota", taskController.GetAccountQuota)            // 获取账号的任务配额
+	
+	// 账号分组关联任务路由
+	Router.POST("/account-groups/:id/tasks/pause", taskController.PauseAccountGroupTasks)   // 暂停账号分组的任务
+	Router.POST("/account-groups/:id/tasks/resume", taskController.ResumeAccountGroupTasks) // 恢复账号分组的任务
+	
+	// 调度器管理路由
+	adminRouter := Router.Group("admin")
+	{
+		adminRouter.GET("/scheduler/leader", schedulerController.GetLeader)    // 获取调度器领导者
+		adminRouter.GET("/scheduler/pause", schedulerController.GetDispatchPause) // 获取全局调度暂停状态
+		adminRouter.POST("/scheduler/pause", schedulerController.PauseDispatch)   // 暂停全局调度
+		adminRouter.POST("/scheduler/resume", schedulerController.ResumeDispatch) // 恢复全局调度
+	}
 	
 	// 工作节点管理路由
 	workerRouter := Router.Group("workers")
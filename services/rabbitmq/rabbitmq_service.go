@@ -28,6 +28,9 @@ type RabbitMQService interface {
 	// 创建任务结果消费者
 	CreateTaskResultConsumer(handler func([]byte) error) error
 	
+	// 创建任务日志消费者
+	CreateTaskLogConsumer(handler func([]byte) error) error
+	
 	// 创建消费者
 	CreateConsumer(exchange, queueName, bindingKey string, handler func([]byte) error) error
 	
@@ -225,6 +228,18 @@ func (s *rabbitMQService) CreateTaskResultConsumer(handler func([]byte) error) e
 	return s.CreateConsumer(exchange, queueName, bindingKey, handler)
 }
 
+// CreateTaskLogConsumer 创建任务日志消费者
+func (s *rabbitMQService) CreateTaskLogConsumer(handler func([]byte) error) error {
+	// 使用结果交换机
+	exchange := global.Config.RabbitMQ.Exchange.Results
+	// 队列名称: task_logs
+	queueName := "task_logs"
+	// 绑定键: task.log
+	bindingKey := "task.log"
+	
+	return s.CreateConsumer(exchange, queueName, bindingKey, handler)
+}
+
 // Close 关闭连接
 func (s *rabbitMQService) Close() error {
 	if s.channel != nil {
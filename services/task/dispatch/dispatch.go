@@ -0,0 +1,69 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+
+	"tg_manager_api/global"
+)
+
+// 调度触发的Redis发布订阅频道，所有API实例共享
+const dispatchChannel = "task:dispatch"
+
+// 本实例待处理的调度信号，容量为1，调度前到达的多次触发合并为一次
+var signals = make(chan struct{}, 1)
+
+// Notify 通知调度器有任务可分配或有工作节点空出容量
+// 调度器只在领导者实例上运行，信号经Redis发送到所有实例，未配置Redis时仅通知本实例
+func Notify(reason string) {
+	if global.Redis == nil {
+		signal()
+		return
+	}
+
+	if err := global.Redis.Publish(context.Background(), dispatchChannel, reason).Err(); err != nil {
+		global.LOG.Warn(fmt.Sprintf("Failed to publish dispatch signal %s: %v", reason, err))
+		signal()
+	}
+}
+
+// Signals 返回本实例的调度信号通道
+func Signals() <-chan struct{} {
+	return signals
+}
+
+// Listen 将Redis中的调度信号转发到本实例，ctx结束后返回
+func Listen(ctx context.Context) error {
+	if global.Redis == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	pubsub := global.Redis.Subscribe(ctx, dispatchChannel)
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			signal()
+		}
+	}
+}
+
+// 发送本地信号，已有待处理信号时直接丢弃
+func signal() {
+	select {
+	case signals <- struct{}{}:
+	default:
+	}
+}
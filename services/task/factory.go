@@ -11,6 +11,15 @@ import (
 var (
 	taskServiceInstance service.TaskServiceI
 	once                sync.Once
+	
+	taskScheduleServiceInstance service.TaskScheduleServiceI
+	scheduleOnce                sync.Once
+	
+	taskWorkflowServiceInstance service.TaskWorkflowServiceI
+	workflowOnce                sync.Once
+	
+	taskBatchServiceInstance service.TaskBatchServiceI
+	batchOnce                sync.Once
 )
 
 // GetTaskService 返回任务服务的单例实例
@@ -31,3 +40,60 @@ func InjectTaskService(c *gin.Context) {
 func GetTaskServiceFromContext(c *gin.Context) service.TaskServiceI {
 	return c.MustGet("taskService").(service.TaskServiceI)
 }
+
+// GetTaskScheduleService 返回周期任务计划服务的单例实例
+func GetTaskScheduleService() service.TaskScheduleServiceI {
+	scheduleOnce.Do(func() {
+		taskScheduleServiceInstance = service.NewTaskScheduleService()
+	})
+	return taskScheduleServiceInstance
+}
+
+// InjectTaskScheduleService 将周期任务计划服务注入到gin上下文中
+func InjectTaskScheduleService(c *gin.Context) {
+	c.Set("taskScheduleService", GetTaskScheduleService())
+	c.Next()
+}
+
+// GetTaskScheduleServiceFromContext 从gin上下文中检索周期任务计划服务
+func GetTaskScheduleServiceFromContext(c *gin.Context) service.TaskScheduleServiceI {
+	return c.MustGet("taskScheduleService").(service.TaskScheduleServiceI)
+}
+
+// GetTaskWorkflowService 返回任务工作流服务的单例实例
+func GetTaskWorkflowService() service.TaskWorkflowServiceI {
+	workflowOnce.Do(func() {
+		taskWorkflowServiceInstance = service.NewTaskWorkflowService()
+	})
+	return taskWorkflowServiceInstance
+}
+
+// InjectTaskWorkflowService 将任务工作流服务注入到gin上下文中
+func InjectTaskWorkflowService(c *gin.Context) {
+	c.Set("taskWorkflowService", GetTaskWorkflowService())
+	c.Next()
+}
+
+// GetTaskWorkflowServiceFromContext 从gin上下文中检索任务工作流服务
+func GetTaskWorkflowServiceFromContext(c *gin.Context) service.TaskWorkflowServiceI {
+	return c.MustGet("taskWorkflowService").(service.TaskWorkflowServiceI)
+}
+
+// GetTaskBatchService 返回批量任务服务的单例实例
+func GetTaskBatchService() service.TaskBatchServiceI {
+	batchOnce.Do(func() {
+		taskBatchServiceInstance = service.NewTaskBatchService()
+	})
+	return taskBatchServiceInstance
+}
+
+// InjectTaskBatchService 将批量任务服务注入到gin上下文中
+func InjectTaskBatchService(c *gin.Context) {
+	c.Set("taskBatchService", GetTaskBatchService())
+	c.Next()
+}
+
+// GetTaskBatchServiceFromContext 从gin上下文中检索批量任务服务
+func GetTaskBatchServiceFromContext(c *gin.Context) service.TaskBatchServiceI {
+	return c.MustGet("taskBatchService").(service.TaskBatchServiceI)
+}
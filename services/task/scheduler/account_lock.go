@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+const (
+	// 默认每个账号同时执行的任务数
+	defaultAccountConcurrency = 1
+	// 锁租约在任务超时时间之外的余量，超时回收前不会提前过期
+	accountLockLeaseGrace = 2 * timeoutCheckInterval
+	// etcd操作超时时间
+	accountLockOpTimeout = 5 * time.Second
+)
+
+// 账号锁的key前缀，与initialize.GetLockKey同样以LockPrefix开头
+// initialize依赖调度器，这里不能反向引用
+func accountLockPrefix(accountID uint) string {
+	return fmt.Sprintf("%saccount/%d/", global.Config.Etcd.LockPrefix, accountID)
+}
+
+// 账号执行槽位的key
+func accountLockKey(accountID uint, slot int) string {
+	return fmt.Sprintf("%s%d", accountLockPrefix(accountID), slot)
+}
+
+// 账号允许同时执行的任务数，账号未单独设置时使用全局配置
+func accountConcurrency(maxConcurrentTasks int) int {
+	if maxConcurrentTasks > 0 {
+		return maxConcurrentTasks
+	}
+	if global.Config.Task.AccountConcurrency > 0 {
+		return global.Config.Task.AccountConcurrency
+	}
+	return defaultAccountConcurrency
+}
+
+// 查询账号的并发上限
+func loadAccountConcurrency(tasks []PendingTask) (map[uint]int, error) {
+	ids := make([]uint, 0, len(tasks))
+	seen := make(map[uint]bool, len(tasks))
+	for _, task := range tasks {
+		if !seen[task.AccountID] {
+			seen[task.AccountID] = true
+			ids = append(ids, task.AccountID)
+		}
+	}
+
+	var accounts []model.Account
+	if err := global.DB.Select("id", "max_concurrent_tasks").Where("id IN ?", ids).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	limits := make(map[uint]int, len(ids))
+	for _, id := range ids {
+		limits[id] = accountConcurrency(0)
+	}
+	for _, account := range accounts {
+		limits[account.ID] = accountConcurrency(account.MaxConcurrentTasks)
+	}
+	return limits, nil
+}
+
+// 为任务占用账号的一个执行槽位，账号的槽位全部被占用时返回false
+// 槽位绑定租约，租约时长为任务超时时间加余量，调度器异常退出时锁会自动过期
+// 未配置etcd时不加锁
+func acquireAccountLock(task *model.Task, limit int) (bool, error) {
+	if global.EtcdClient == nil {
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), accountLockOpTimeout)
+	defer cancel()
+
+	ttl := time.Duration(task.TimeoutSec)*time.Second + accountLockLeaseGrace
+	lease, err := global.EtcdClient.Grant(ctx, int64(ttl.Seconds()))
+	if err != nil {
+		return false, fmt.Errorf("failed to grant account lock lease: %w", err)
+	}
+
+	for slot := 0; slot < limit; slot++ {
+		key := accountLockKey(task.AccountID, slot)
+		resp, err := global.EtcdClient.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+			Then(clientv3.OpPut(key, task.TaskID, clientv3.WithLease(lease.ID))).
+			Commit()
+		if err != nil {
+			global.EtcdClient.Revoke(ctx, lease.ID)
+			return false, fmt.Errorf("failed to acquire account lock: %w", err)
+		}
+		if resp.Succeeded {
+			return true, nil
+		}
+	}
+
+	// 没有空闲槽位
+	global.EtcdClient.Revoke(ctx, lease.ID)
+	return false, nil
+}
+
+// 释放任务占用的账号槽位，仅删除仍由该任务持有的槽位
+func releaseAccountLock(accountID uint, taskID string) {
+	if global.EtcdClient == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), accountLockOpTimeout)
+	defer cancel()
+
+	resp, err := global.EtcdClient.Get(ctx, accountLockPrefix(accountID), clientv3.WithPrefix())
+	if err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to read account %d locks: %v", accountID, err))
+		return
+	}
+
+	for _, kv := range resp.Kvs {
+		if string(kv.Value) != taskID {
+			continue
+		}
+		// 撤销租约同时删除槽位
+		if _, err := global.EtcdClient.Revoke(ctx, clientv3.LeaseID(kv.Lease)); err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to release account %d lock held by task %s: %v", accountID, taskID, err))
+		}
+	}
+}
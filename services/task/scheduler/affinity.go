@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/service"
+)
+
+// AffinityMove 亲和关系迁移，把Count个账号从From节点迁移到To节点
+type AffinityMove struct {
+	From  string
+	To    string
+	Count int
+}
+
+// PlanAffinityRebalance 计算使各在线节点亲和账号数均衡的迁移方案
+// 每次从账号最多的节点迁移一个到账号最少的节点，直到相差不超过1，迁移数量最少
+func PlanAffinityRebalance(counts map[string]int, workers []string) []AffinityMove {
+	if len(workers) < 2 {
+		return nil
+	}
+
+	sorted := append([]string(nil), workers...)
+	sort.Strings(sorted)
+	current := make(map[string]int, len(sorted))
+	for _, worker := range sorted {
+		current[worker] = counts[worker]
+	}
+
+	moved := make(map[[2]string]int)
+	var order [][2]string
+	for {
+		most, least := sorted[0], sorted[0]
+		for _, worker := range sorted[1:] {
+			if current[worker] > current[most] {
+				most = worker
+			}
+			if current[worker] < current[least] {
+				least = worker
+			}
+		}
+		if current[most]-current[least] <= 1 {
+			break
+		}
+
+		current[most]--
+		current[least]++
+		key := [2]string{most, least}
+		if _, ok := moved[key]; !ok {
+			order = append(order, key)
+		}
+		moved[key]++
+	}
+
+	moves := make([]AffinityMove, 0, len(order))
+	for _, key := range order {
+		moves = append(moves, AffinityMove{From: key[0], To: key[1], Count: moved[key]})
+	}
+	return moves
+}
+
+// 查询待调度任务所属账号的亲和工作节点
+func loadAffinities(tasks []PendingTask) (map[uint]string, error) {
+	ids := make([]uint, 0, len(tasks))
+	for i := range tasks {
+		ids = append(ids, tasks[i].AccountID)
+	}
+
+	var affinities []model.AccountWorkerAffinity
+	if err := global.DB.Where("account_id IN ?", ids).Find(&affinities).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]string, len(affinities))
+	for _, affinity := range affinities {
+		result[affinity.AccountID] = affinity.WorkerID
+	}
+	return result, nil
+}
+
+// 记录账号最近一次分配到的工作节点
+func recordAffinity(accountID uint, workerID string) {
+	now := time.Now()
+	result := global.DB.Model(&model.AccountWorkerAffinity{}).
+		Where("account_id = ?", accountID).
+		Updates(map[string]interface{}{
+			"worker_id":        workerID,
+			"last_assigned_at": now,
+		})
+	if result.Error == nil && result.RowsAffected == 0 {
+		result = global.DB.Create(&model.AccountWorkerAffinity{
+			AccountID:      accountID,
+			WorkerID:       workerID,
+			LastAssignedAt: now,
+		})
+	}
+	if result.Error != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to record affinity of account %d to worker %s: %v", accountID, workerID, result.Error))
+	}
+}
+
+// 在线工作节点变化时重新平衡亲和关系
+// 删除已离线节点的亲和关系，并把账号从亲和账号多的节点迁移到新加入的节点
+func (s *TaskScheduler) rebalanceAffinities(onlineWorkers []*model.Worker) {
+	workers := make([]string, 0, len(onlineWorkers))
+	for _, worker := range onlineWorkers {
+		workers = append(workers, worker.WorkerID)
+	}
+	sort.Strings(workers)
+	key := strings.Join(workers, ",")
+	if key == s.affinityWorkers {
+		return
+	}
+
+	// 离线节点的账号在下次分配时重新选择节点
+	query := global.DB.Unscoped()
+	if len(workers) > 0 {
+		query = query.Where("worker_id NOT IN ?", workers)
+	} else {
+		query = query.Where("1 = 1")
+	}
+	if err := query.Delete(&model.AccountWorkerAffinity{}).Error; err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to remove affinities of offline workers: %v", err))
+		return
+	}
+
+	var rows []struct {
+		WorkerID string
+		Count    int
+	}
+	if err := global.DB.Model(&model.AccountWorkerAffinity{}).
+		Select("worker_id, COUNT(*) AS count").
+		Group("worker_id").
+		Scan(&rows).Error; err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to count worker affinities: %v", err))
+		return
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.WorkerID] = row.Count
+	}
+
+	// 优先迁移最久未分配的账号
+	for _, move := range PlanAffinityRebalance(counts, workers) {
+		var accountIDs []uint
+		if err := global.DB.Model(&model.AccountWorkerAffinity{}).
+			Where("worker_id = ?", move.From).
+			Order("last_assigned_at ASC").
+			Limit(move.Count).
+			Pluck("account_id", &accountIDs).Error; err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to select affinities of worker %s: %v", move.From, err))
+			return
+		}
+		if err := global.DB.Model(&model.AccountWorkerAffinity{}).
+			Where("account_id IN ?", accountIDs).
+			Update("worker_id", move.To).Error; err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to move affinities from worker %s to %s: %v", move.From, move.To, err))
+			return
+		}
+		global.LOG.Info(fmt.Sprintf("Moved %d account affinities from worker %s to %s", len(accountIDs), move.From, move.To))
+	}
+
+	s.affinityWorkers = key
+}
+
+// 优先选择账号亲和的工作节点，亲和节点离线、满载或缺少所需标签时轮询选择其他节点
+// 返回选中节点的下标，没有可用节点时返回-1，sticky表示是否选中了亲和节点
+func pickWorker(workers []*model.Worker, start int, tags []string, preferred string) (idx int, sticky bool) {
+	if preferred != "" {
+		for i, worker := range workers {
+			if worker.WorkerID == preferred && service.WorkerHasTags(worker, tags) {
+				return i, true
+			}
+		}
+	}
+	return selectWorker(workers, start, tags), false
+}
@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+
+	"tg_manager_api/global"
+)
+
+const (
+	// 默认选举租约时长(秒)，领导者失联超过该时间后其他实例接管
+	defaultLeaderTTL = 15
+	// 选举出错后的重试间隔
+	leaderRetryInterval = 5 * time.Second
+	// 主动退出领导者身份的超时时间
+	leaderResignTimeout = 3 * time.Second
+)
+
+// LeaderInfo 调度器领导者信息
+type LeaderInfo struct {
+	Election bool   `json:"election"`  // 是否启用选举，未配置etcd时每个实例都运行调度器
+	Leader   string `json:"leader"`    // 当前领导者实例标识，为空表示尚未选出
+	Instance string `json:"instance"`  // 当前实例标识
+	IsLeader bool   `json:"is_leader"` // 当前实例是否为领导者
+}
+
+// 选举key前缀
+func leaderElectionPrefix() string {
+	return global.Config.Etcd.LockPrefix + "scheduler/leader"
+}
+
+// InstanceID 当前实例标识，由主机名和服务端口组成
+func InstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", hostname, global.Config.System.Port)
+}
+
+// GetLeader 获取当前调度器领导者
+func GetLeader(ctx context.Context) (*LeaderInfo, error) {
+	info := &LeaderInfo{Instance: InstanceID()}
+	if global.EtcdClient == nil {
+		info.Leader = info.Instance
+		info.IsLeader = true
+		return info, nil
+	}
+
+	// 最早创建的key即为领导者，与concurrency.Election.Leader一致
+	resp, err := global.EtcdClient.Get(ctx, leaderElectionPrefix(), clientv3.WithFirstCreate()...)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Election = true
+	if len(resp.Kvs) > 0 {
+		info.Leader = string(resp.Kvs[0].Value)
+		info.IsLeader = info.Leader == info.Instance
+	}
+	return info, nil
+}
+
+// 参与领导者选举，当选后运行调度循环，租约失效时停止调度并重新参选
+// 未配置etcd时直接运行调度循环
+func (s *TaskScheduler) leaderLoop() {
+	if global.EtcdClient == nil {
+		s.lead(nil)
+		return
+	}
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		default:
+		}
+
+		if err := s.campaign(); err != nil {
+			global.LOG.Error(fmt.Sprintf("Scheduler leader election failed: %v", err))
+			select {
+			case <-time.After(leaderRetryInterval):
+			case <-s.stopChan:
+				return
+			}
+		}
+	}
+}
+
+// 参选一轮，当选后一直运行到租约失效或调度器停止
+func (s *TaskScheduler) campaign() error {
+	ttl := global.Config.Task.LeaderTTL
+	if ttl <= 0 {
+		ttl = defaultLeaderTTL
+	}
+
+	session, err := concurrency.NewSession(global.EtcdClient, concurrency.WithTTL(ttl))
+	if err != nil {
+		return fmt.Errorf("failed to create election session: %w", err)
+	}
+	defer session.Close()
+
+	// 调度器停止时中断参选
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	election := concurrency.NewElection(session, leaderElectionPrefix())
+	if err := election.Campaign(ctx, InstanceID()); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to campaign: %w", err)
+	}
+
+	global.LOG.Info(fmt.Sprintf("Instance %s elected as scheduler leader", InstanceID()))
+	s.lead(session.Done())
+
+	select {
+	case <-session.Done():
+		// 租约已失效，领导者key随之删除
+		return nil
+	default:
+	}
+
+	// 调度器停止时主动退出，其他实例无需等待租约过期即可接管
+	resignCtx, resignCancel := context.WithTimeout(context.Background(), leaderResignTimeout)
+	defer resignCancel()
+	if err := election.Resign(resignCtx); err != nil {
+		global.LOG.Warn(fmt.Sprintf("Failed to resign scheduler leadership: %v", err))
+	}
+	return nil
+}
+
+// 运行调度、超时检测、存活检测、发件箱中继和归档循环，直到失去领导者身份或调度器停止
+func (s *TaskScheduler) lead(lost <-chan struct{}) {
+	stop := make(chan struct{})
+	go s.scheduleLoop(stop)
+	go s.timeoutLoop(stop)
+	go s.livenessLoop(stop)
+	go s.outboxLoop(stop)
+	go s.retentionLoop(stop)
+
+	select {
+	case <-lost:
+		global.LOG.Warn(fmt.Sprintf("Instance %s lost scheduler leadership", InstanceID()))
+	case <-s.stopChan:
+	}
+	close(stop)
+}
@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"tg_manager_api/config"
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/dispatch"
+	"tg_manager_api/services/task/service"
+	workerSvc "tg_manager_api/services/worker/service"
+)
+
+const (
+	// 默认心跳间隔
+	defaultHeartbeatInterval = 30 * time.Second
+	// 默认判定离线前允许错过的心跳次数
+	defaultMissedHeartbeats = 3
+	// 工作节点失联后其任务分配记录的状态
+	assignmentStatusLost = "lost"
+)
+
+// 心跳间隔，同时作为存活检测间隔
+func heartbeatInterval(cfg config.Worker) time.Duration {
+	if cfg.HeartbeatInterval > 0 {
+		return time.Duration(cfg.HeartbeatInterval) * time.Second
+	}
+	return defaultHeartbeatInterval
+}
+
+// LivenessTimeout 工作节点超过该时长没有心跳即判定为离线
+func LivenessTimeout(cfg config.Worker) time.Duration {
+	missed := cfg.MissedHeartbeats
+	if missed <= 0 {
+		missed = defaultMissedHeartbeats
+	}
+	return heartbeatInterval(cfg) * time.Duration(missed)
+}
+
+// lostTask 失联工作节点上未完成的任务及其分配记录
+type lostTask struct {
+	model.Task
+	AssignmentID uint `gorm:"column:assignment_id"`
+}
+
+// 存活检测循环，仅在领导者实例运行
+func (s *TaskScheduler) livenessLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval(global.Config.Worker))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sweepDeadWorkers()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// 将心跳超时的在线工作节点标记为离线，并回收其任务
+func (s *TaskScheduler) sweepDeadWorkers() {
+	cutoff := time.Now().Add(-LivenessTimeout(global.Config.Worker))
+
+	var workers []model.Worker
+	if err := global.DB.Where("status = ? AND last_heartbeat < ?", "online", cutoff).
+		Find(&workers).Error; err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to fetch dead workers: %v", err))
+		return
+	}
+
+	lost := 0
+	for i := range workers {
+		ok, err := s.markWorkerLost(&workers[i], cutoff)
+		if err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to mark worker %s offline: %v", workers[i].WorkerID, err))
+			continue
+		}
+		if ok {
+			lost++
+		}
+	}
+
+	// 重新排队的任务可立即分配给其他工作节点
+	if lost > 0 {
+		dispatch.Notify("worker_lost")
+	}
+}
+
+// 标记工作节点离线并回收其任务，节点在检测期间恢复心跳时返回false
+func (s *TaskScheduler) markWorkerLost(worker *model.Worker, cutoff time.Time) (bool, error) {
+	// 以心跳时间为条件更新，避免覆盖刚到达的心跳
+	// 标记离线后分配任务时的工作节点更新不再匹配，不会再有新任务分配给该节点
+	result := global.DB.Model(&model.Worker{}).
+		Where("worker_id = ? AND status = ? AND last_heartbeat < ?", worker.WorkerID, "online", cutoff).
+		Updates(map[string]interface{}{
+			"status":        "offline",
+			"current_tasks": 0,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	var tasks []lostTask
+	if err := global.DB.Table("tasks").
+		Select("tasks.*, task_assignments.id AS assignment_id").
+		Joins("JOIN task_assignments ON task_assignments.task_id = tasks.task_id AND task_assignments.completed_at IS NULL AND task_assignments.deleted_at IS NULL").
+		Where("task_assignments.worker_id = ? AND tasks.deleted_at IS NULL", worker.WorkerID).
+		Find(&tasks).Error; err != nil {
+		return true, fmt.Errorf("failed to fetch tasks of worker: %w", err)
+	}
+
+	var requeued []string
+	for i := range tasks {
+		ok, err := s.recoverLostTask(&tasks[i], worker.WorkerID)
+		if err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to recover task %s from worker %s: %v", tasks[i].TaskID, worker.WorkerID, err))
+			continue
+		}
+		if ok {
+			requeued = append(requeued, tasks[i].TaskID)
+		}
+	}
+
+	global.LOG.Warn(fmt.Sprintf("Worker %s missed heartbeats since %s, marked offline and requeued %d tasks",
+		worker.WorkerID, worker.LastHeartbeat.Format(time.RFC3339), len(requeued)))
+	workerSvc.PublishWorkerEvent(&workerSvc.WorkerEvent{
+		WorkerID:      worker.WorkerID,
+		Status:        "offline",
+		Reason:        "Missed heartbeats",
+		LastHeartbeat: worker.LastHeartbeat,
+		RequeuedTasks: requeued,
+		Time:          time.Now(),
+	})
+	return true, nil
+}
+
+// 回收失联工作节点上的任务并关闭分配记录，返回任务是否重新排队
+// 已分配和执行中的任务重新排队，等待确认取消的任务直接完成取消
+// 失联不计入重试次数，重新排队提交后通知失联节点停止执行，避免节点恢复后与新分配重复执行
+func (s *TaskScheduler) recoverLostTask(task *lostTask, workerID string) (bool, error) {
+	now := time.Now()
+	reason := fmt.Sprintf("Worker %s lost", workerID)
+
+	newStatus := service.TaskStatusPending
+	updates := map[string]interface{}{
+		"attempt":          gorm.Expr("GREATEST(attempt - 1, 0)"),
+		"assigned_at":      nil,
+		"started_at":       nil,
+		"progress":         0,
+		"progress_message": "",
+	}
+	if task.Status == string(service.TaskStatusCanceling) {
+		newStatus = service.TaskStatusCanceled
+		reason = fmt.Sprintf("Task canceled by user, worker %s lost before confirming", workerID)
+		updates = map[string]interface{}{
+			"completed_at":  now,
+			"error_message": reason,
+		}
+	}
+
+	// 任务已在其他流程中结束时只关闭分配记录
+	active := task.Status == string(service.TaskStatusAssigned) || task.Status == string(service.TaskStatusProcessing) ||
+		task.Status == string(service.TaskStatusCanceling)
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if active {
+			if err := service.TransitionTaskStatus(tx, &task.Task, newStatus, service.TaskActorScheduler, reason, updates); err != nil {
+				return err
+			}
+
+			// 丢弃尚未发送给失联节点的任务消息
+			if err := tx.Where("task_id = ? AND status = ?", task.TaskID, outboxStatusPending).
+				Delete(&model.TaskOutbox{}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&model.TaskAssignment{}).
+			Where("id = ?", task.AssignmentID).
+			Updates(map[string]interface{}{
+				"status":       assignmentStatusLost,
+				"completed_at": now,
+			}).Error
+	})
+	if err == global.ErrorTaskStatusConflict {
+		// 任务状态已被结果或其他操作更新
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !active {
+		return false, nil
+	}
+
+	releaseAccountLock(task.AccountID, task.TaskID)
+	service.PublishTaskEvent(service.NewTaskEvent(&task.Task, string(newStatus)))
+	if newStatus == service.TaskStatusCanceled {
+		service.AppendTaskLog(task.TaskID, workerID, service.TaskLogWarn, "%s", reason)
+		if task.WorkflowID != "" {
+			if err := s.workflowService.ResolveDependents(context.Background(), task.TaskID, false); err != nil {
+				global.LOG.Error(fmt.Sprintf("Failed to resolve dependents of task %s: %v", task.TaskID, err))
+			}
+		}
+		return false, nil
+	}
+
+	service.AppendTaskLog(task.TaskID, workerID, service.TaskLogWarn, "Attempt %d/%d lost with worker %s, requeued without counting against max attempts", task.Attempt, task.MaxAttempts, workerID)
+
+	// 任务已重新排队，通知发送失败时仅记录日志
+	cancelData, err := json.Marshal(map[string]interface{}{
+		"task_id":   task.TaskID,
+		"worker_id": workerID,
+		"reason":    "worker_lost",
+	})
+	if err == nil {
+		err = s.rabbitMQ.PublishTaskCancel(cancelData)
+	}
+	if err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to publish cancel of task %s to lost worker %s: %v", task.TaskID, workerID, err))
+	}
+	return true, nil
+}
@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// 发件箱消息状态
+const (
+	outboxStatusPending = "pending" // 待发送
+	outboxStatusSent    = "sent"    // 已发送
+)
+
+const (
+	// 兜底轮询发件箱的间隔
+	outboxPollInterval = 5 * time.Second
+	// 每轮最多发送的消息数
+	outboxBatchSize = 100
+	// 发送失败后重试间隔的上限
+	outboxMaxBackoff = 5 * time.Minute
+	// 已发送消息的保留时长
+	outboxRetention = 24 * time.Hour
+	// 清理已发送消息的间隔
+	outboxPurgeInterval = time.Hour
+)
+
+// OutboxBackoff 第attempts次发送失败后的重试间隔，从1秒开始每次翻倍，最长5分钟
+func OutboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := time.Second
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// 在事务中写入任务消息，事务提交后由中继发送
+func enqueueTaskMessage(tx *gorm.DB, task *model.Task, workerID string) error {
+	taskMessage := map[string]interface{}{
+		"task_id":    task.TaskID,
+		"task_type":  task.TaskType,
+		"account_id": task.AccountID,
+		"params":     task.Params,
+		"worker_id":  workerID,
+		"timeout":    task.TimeoutSec,
+		"attempt":    task.Attempt + 1,
+		"created_at": task.CreatedAt,
+	}
+
+	// 序列化任务消息
+	taskData, err := json.Marshal(taskMessage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task message: %w", err)
+	}
+
+	return tx.Create(&model.TaskOutbox{
+		TaskID:        task.TaskID,
+		RoutingKey:    task.TaskType,
+		Payload:       string(taskData),
+		Status:        outboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// 唤醒发件箱中继，已有待处理的唤醒时直接丢弃
+func (s *TaskScheduler) wakeOutbox() {
+	select {
+	case s.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// 发件箱中继循环，仅在领导者实例运行，stop关闭后退出
+func (s *TaskScheduler) outboxLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	purge := time.NewTicker(outboxPurgeInterval)
+	defer purge.Stop()
+
+	s.relayOutbox()
+	for {
+		select {
+		case <-ticker.C:
+			s.relayOutbox()
+		case <-s.outboxWake:
+			s.relayOutbox()
+		case <-purge.C:
+			purgeOutbox()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// 发送到期的待发送消息，发送失败时按退避间隔重试
+func (s *TaskScheduler) relayOutbox() {
+	for {
+		var messages []model.TaskOutbox
+		if err := global.DB.Where("status = ? AND next_attempt_at <= ?", outboxStatusPending, time.Now()).
+			Order("id ASC").
+			Limit(outboxBatchSize).
+			Find(&messages).Error; err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to fetch outbox messages: %v", err))
+			return
+		}
+
+		for i := range messages {
+			if !s.relayMessage(&messages[i]) {
+				// RabbitMQ不可用时后续消息大概率也会失败，等待下一轮
+				return
+			}
+		}
+
+		if len(messages) < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// 发送单条消息并记录结果，返回是否发送成功
+func (s *TaskScheduler) relayMessage(message *model.TaskOutbox) bool {
+	publishErr := s.rabbitMQ.PublishTask(message.RoutingKey, []byte(message.Payload))
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts": gorm.Expr("attempts + 1"),
+	}
+	if publishErr == nil {
+		updates["status"] = outboxStatusSent
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+	} else {
+		updates["next_attempt_at"] = now.Add(OutboxBackoff(message.Attempts + 1))
+		updates["last_error"] = publishErr.Error()
+		global.LOG.Error(fmt.Sprintf("Failed to publish outbox message %d of task %s (attempt %d): %v",
+			message.ID, message.TaskID, message.Attempts+1, publishErr))
+	}
+
+	// 记录失败时消息会被再次发送，工作节点需按任务ID和执行次数去重
+	if err := global.DB.Model(&model.TaskOutbox{}).Where("id = ?", message.ID).Updates(updates).Error; err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to update outbox message %d: %v", message.ID, err))
+	}
+	return publishErr == nil
+}
+
+// 删除超过保留时长的已发送消息
+func purgeOutbox() {
+	if err := global.DB.Unscoped().
+		Where("status = ? AND sent_at < ?", outboxStatusSent, time.Now().Add(-outboxRetention)).
+		Delete(&model.TaskOutbox{}).Error; err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to purge sent outbox messages: %v", err))
+	}
+}
@@ -0,0 +1,204 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"tg_manager_api/config"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/service"
+)
+
+// 调度策略名称
+const (
+	PolicyPriority = "priority" // 按有效优先级调度
+	PolicyFair     = "fair"     // 按账号分组或租户加权公平调度
+)
+
+// 公平调度的分组依据
+const (
+	FairKeyAccountGroup = "account_group" // 按账号分组
+	FairKeyTenant       = "tenant"        // 按创建账号的用户
+)
+
+// PendingTask 待调度任务及其账号归属
+type PendingTask struct {
+	model.Task
+	AccountGroupID uint `gorm:"column:account_group_id"`
+	TenantID       uint `gorm:"column:tenant_id"`
+}
+
+// SchedulingPolicy 调度策略，决定一轮调度中待处理任务的分配顺序
+type SchedulingPolicy interface {
+	// 策略名称
+	Name() string
+
+	// 返回排序后的任务列表，排在前面的任务优先分配
+	Order(tasks []PendingTask, now time.Time) []PendingTask
+}
+
+// SchedulingPolicyFactory 根据配置创建调度策略
+type SchedulingPolicyFactory func(cfg config.TaskScheduling) SchedulingPolicy
+
+// 已注册的调度策略
+var schedulingPolicies = map[string]SchedulingPolicyFactory{
+	PolicyPriority: func(cfg config.TaskScheduling) SchedulingPolicy {
+		return &priorityPolicy{aging: agingInterval(cfg)}
+	},
+	PolicyFair: func(cfg config.TaskScheduling) SchedulingPolicy {
+		return &fairPolicy{
+			priorityPolicy: priorityPolicy{aging: agingInterval(cfg)},
+			key:            cfg.FairKey,
+			weights:        cfg.Weights,
+		}
+	},
+}
+
+// RegisterSchedulingPolicy 注册调度策略，同名策略会被覆盖
+func RegisterSchedulingPolicy(name string, factory SchedulingPolicyFactory) {
+	schedulingPolicies[name] = factory
+}
+
+// NewSchedulingPolicy 根据配置创建调度策略，未配置时使用优先级策略
+func NewSchedulingPolicy(cfg config.TaskScheduling) (SchedulingPolicy, error) {
+	name := cfg.Policy
+	if name == "" {
+		name = PolicyPriority
+	}
+
+	factory, ok := schedulingPolicies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scheduling policy %q", name)
+	}
+	return factory(cfg), nil
+}
+
+// 优先级老化间隔
+func agingInterval(cfg config.TaskScheduling) time.Duration {
+	return time.Duration(cfg.AgingInterval) * time.Second
+}
+
+// priorityPolicy 按有效优先级从高到低调度，有效优先级相同时先创建的先调度
+type priorityPolicy struct {
+	aging time.Duration
+}
+
+// Name 策略名称
+func (p *priorityPolicy) Name() string {
+	return PolicyPriority
+}
+
+// Order 按有效优先级排序
+func (p *priorityPolicy) Order(tasks []PendingTask, now time.Time) []PendingTask {
+	p.sort(tasks, now)
+	return tasks
+}
+
+// EffectivePriority 计算任务的有效优先级，每等待一个老化间隔加1
+// 等待时间从任务可被调度时开始计算，即创建时间、计划执行时间和下次重试时间中最晚者
+func EffectivePriority(task *model.Task, aging time.Duration, now time.Time) int {
+	if aging <= 0 {
+		return task.Priority
+	}
+
+	since := service.TaskReadyAt(task)
+	if !now.After(since) {
+		return task.Priority
+	}
+	return task.Priority + int(now.Sub(since)/aging)
+}
+
+// 按有效优先级和创建时间排序
+func (p *priorityPolicy) sort(tasks []PendingTask, now time.Time) {
+	priorities := make(map[uint]int, len(tasks))
+	for i := range tasks {
+		priorities[tasks[i].ID] = EffectivePriority(&tasks[i].Task, p.aging, now)
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		pi, pj := priorities[tasks[i].ID], priorities[tasks[j].ID]
+		if pi != pj {
+			return pi > pj
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
+// fairPolicy 加权公平调度
+// 按账号分组或租户划分队列，队列内按有效优先级排序，队列间按权重交替分配，
+// 权重为2的队列每轮分配的任务数是权重为1的队列的两倍，单个大批量任务不会饿死其他队列
+type fairPolicy struct {
+	priorityPolicy
+	key     string
+	weights map[string]int
+}
+
+// Name 策略名称
+func (p *fairPolicy) Name() string {
+	return PolicyFair
+}
+
+// Order 按虚拟完成时间合并各队列，每分配一个任务队列的虚拟时间增加1/权重
+func (p *fairPolicy) Order(tasks []PendingTask, now time.Time) []PendingTask {
+	p.sort(tasks, now)
+
+	queues := make(map[uint][]PendingTask)
+	var keys []uint
+	for _, task := range tasks {
+		key := p.queueKey(&task)
+		if _, ok := queues[key]; !ok {
+			keys = append(keys, key)
+		}
+		queues[key] = append(queues[key], task)
+	}
+
+	finish := make(map[uint]float64, len(keys))
+	ordered := make([]PendingTask, 0, len(tasks))
+	for len(ordered) < len(tasks) {
+		// 选择下一个任务虚拟完成时间最小的队列，相同时选择队首任务优先级更高的队列
+		var next uint
+		best := -1.0
+		for _, key := range keys {
+			if len(queues[key]) == 0 {
+				continue
+			}
+			tag := finish[key] + 1/float64(p.weight(key))
+			if best < 0 || tag < best || (tag == best && p.before(&queues[key][0], &queues[next][0], now)) {
+				next, best = key, tag
+			}
+		}
+
+		finish[next] = best
+		ordered = append(ordered, queues[next][0])
+		queues[next] = queues[next][1:]
+	}
+	return ordered
+}
+
+// 任务所属的队列
+func (p *fairPolicy) queueKey(task *PendingTask) uint {
+	if p.key == FairKeyTenant {
+		return task.TenantID
+	}
+	return task.AccountGroupID
+}
+
+// 队列权重，未配置时为1
+func (p *fairPolicy) weight(key uint) int {
+	if weight := p.weights[strconv.FormatUint(uint64(key), 10)]; weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// a是否应排在b之前
+func (p *fairPolicy) before(a, b *PendingTask, now time.Time) bool {
+	pa := EffectivePriority(&a.Task, p.aging, now)
+	pb := EffectivePriority(&b.Task, p.aging, now)
+	if pa != pb {
+		return pa > pb
+	}
+	return a.ID < b.ID
+}
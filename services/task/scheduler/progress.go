@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/service"
+)
+
+// 进度事件类型，工作节点在结果消息中以type字段标识
+const taskEventProgress = "progress"
+
+// 处理工作节点上报的任务进度
+// 首次上报进度时将任务从assigned推进到processing并记录开始时间
+// 无法解析的进度和不存在或已归档任务的进度记录后确认，只有数据库错误时重新投递
+func (s *TaskScheduler) processTaskProgress(data []byte) error {
+	var event struct {
+		TaskID   string `json:"task_id"`
+		WorkerID string `json:"worker_id"`
+		Progress int    `json:"progress"`
+		Message  string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		global.LOG.Error(fmt.Sprintf("Dropping unparseable task progress %s: %v", string(data), err))
+		return nil
+	}
+
+	var task model.Task
+	if err := global.DB.Where("task_id = ?", event.TaskID).First(&task).Error; err == gorm.ErrRecordNotFound {
+		global.LOG.Warn(fmt.Sprintf("Dropping progress of unknown or archived task %s from worker %s", event.TaskID, event.WorkerID))
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to find task: %w", err)
+	}
+
+	// 已结束或重新排队的任务忽略迟到的进度
+	if task.Status != "assigned" && task.Status != "processing" && task.Status != "canceling" {
+		return nil
+	}
+
+	// 工作节点失联后任务已重新排队或分配给其他节点，忽略其迟到的进度
+	var open int64
+	if err := global.DB.Model(&model.TaskAssignment{}).
+		Where("task_id = ? AND worker_id = ? AND completed_at IS NULL", event.TaskID, event.WorkerID).
+		Count(&open).Error; err != nil {
+		return fmt.Errorf("failed to find task assignment: %w", err)
+	}
+	if open == 0 {
+		return nil
+	}
+
+	progress := event.Progress
+	if progress < 0 {
+		progress = 0
+	} else if progress > 100 {
+		progress = 100
+	}
+
+	updates := map[string]interface{}{
+		"progress":         progress,
+		"progress_message": event.Message,
+	}
+	if task.Status == string(service.TaskStatusAssigned) {
+		// 首次上报进度，任务开始执行
+		updates["started_at"] = time.Now()
+		err := service.TransitionTaskStatus(global.DB, &task, service.TaskStatusProcessing, event.WorkerID, "Worker reported progress", updates)
+		if err == global.ErrorTaskStatusConflict {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update task progress: %w", err)
+		}
+	} else {
+		// 以当前状态为条件更新，避免覆盖同时到达的执行结果
+		result := global.DB.Model(&model.Task{}).
+			Where("task_id = ? AND status = ?", task.TaskID, task.Status).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update task progress: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+	}
+
+	task.Progress = progress
+	task.ProgressMessage = event.Message
+	service.PublishTaskEvent(service.NewTaskEvent(&task, task.Status))
+	return nil
+}
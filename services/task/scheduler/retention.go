@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tg_manager_api/global"
+	"tg_manager_api/services/task/service"
+)
+
+// 默认归档运行间隔
+const defaultRetentionInterval = time.Hour
+
+// 归档运行间隔
+func retentionInterval() time.Duration {
+	if global.Config.Task.Retention.Interval > 0 {
+		return time.Duration(global.Config.Task.Retention.Interval) * time.Second
+	}
+	return defaultRetentionInterval
+}
+
+// 归档循环，仅在领导者实例运行，未配置保留天数时不运行
+func (s *TaskScheduler) retentionLoop(stop <-chan struct{}) {
+	if global.Config.Task.Retention.Days <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(retentionInterval())
+	defer ticker.Stop()
+
+	for {
+		s.archiveExpiredTasks(stop)
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// 分批归档超过保留期的任务，直到没有可归档的任务或调度器停止
+func (s *TaskScheduler) archiveExpiredTasks(stop <-chan struct{}) {
+	cfg := global.Config.Task.Retention
+	cutoff := time.Now().AddDate(0, 0, -cfg.Days)
+	statuses := service.ArchiveStatuses(cfg)
+	batchSize := service.ArchiveBatchSize(cfg)
+
+	total := 0
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		archived, err := service.ArchiveTasks(context.Background(), cutoff, statuses, batchSize)
+		if err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to archive tasks: %v", err))
+			break
+		}
+		total += archived
+		if archived < batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		global.LOG.Info(fmt.Sprintf("Archived %d tasks finished before %s", total, cutoff.Format(time.RFC3339)))
+	}
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/service"
+)
+
+// ErrorClassTimeout 任务执行超时的错误类型
+const ErrorClassTimeout = "TIMEOUT"
+
+// RetryDelay 计算第attempt次尝试失败后的重试等待时间
+// 等待时间从base开始按2的幂次递增，不超过maxDelay
+func RetryDelay(baseSec, maxSec, attempt int) time.Duration {
+	if baseSec <= 0 {
+		return 0
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := time.Duration(baseSec) * time.Second
+	limit := time.Duration(maxSec) * time.Second
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if maxSec > 0 && delay >= limit {
+			return limit
+		}
+	}
+	if maxSec > 0 && delay > limit {
+		return limit
+	}
+	return delay
+}
+
+// IsRetryable 判断任务失败后是否还可以重试
+func IsRetryable(task *model.Task, errorClass string) bool {
+	if task.Attempt >= task.MaxAttempts {
+		return false
+	}
+
+	// 未限定错误类型时，所有错误均可重试
+	if strings.TrimSpace(task.RetryableErrors) == "" {
+		return true
+	}
+
+	for _, class := range strings.Split(task.RetryableErrors, ",") {
+		if strings.EqualFold(strings.TrimSpace(class), errorClass) {
+			return true
+		}
+	}
+	return false
+}
+
+// 计算任务下次重试时间
+func nextRetryAt(task *model.Task, now time.Time) time.Time {
+	return now.Add(RetryDelay(task.RetryBackoffSec, task.RetryBackoffMaxSec, task.Attempt))
+}
+
+// 将失败的任务重新放回待处理队列，等待退避时间后再次调度，返回下次重试时间
+func requeueForRetry(tx *gorm.DB, task *model.Task, actor, errorMsg string, now time.Time) (time.Time, error) {
+	retryAt := nextRetryAt(task, now)
+	reason := fmt.Sprintf("Attempt %d/%d failed, retrying: %s", task.Attempt, task.MaxAttempts, errorMsg)
+	if err := service.TransitionTaskStatus(tx, task, service.TaskStatusPending, actor, reason, map[string]interface{}{
+		"error_message": errorMsg,
+		"assigned_at":   nil,
+		"started_at":    nil,
+		"next_retry_at": retryAt,
+	}); err != nil {
+		return time.Time{}, err
+	}
+	return retryAt, nil
+}
+
+// 重新排队的事务提交后通知订阅者并记录日志
+func announceRetry(task *model.Task, errorMsg string, retryAt time.Time) {
+	task.ErrorMessage = errorMsg
+	service.PublishTaskEvent(service.NewTaskEvent(task, string(service.TaskStatusPending)))
+	service.AppendTaskLog(task.TaskID, "", service.TaskLogWarn, "Attempt %d/%d failed, retrying at %s: %s",
+		task.Attempt, task.MaxAttempts, retryAt.Format(time.RFC3339), errorMsg)
+
+	global.LOG.Info(fmt.Sprintf("Task %s failed on attempt %d/%d, retrying at %s",
+		task.TaskID, task.Attempt, task.MaxAttempts, retryAt.Format(time.RFC3339)))
+}
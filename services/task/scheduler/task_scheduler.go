@@ -3,33 +3,61 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 	
+	"gorm.io/gorm"
+	
+	"tg_manager_api/config"
 	"tg_manager_api/global"
 	"tg_manager_api/model"
 	"tg_manager_api/services/rabbitmq"
+	"tg_manager_api/services/task/dispatch"
 	"tg_manager_api/services/task/service"
 	workerSvc "tg_manager_api/services/worker/service"
 )
 
+const (
+	// 兜底调度间隔
+	dispatchSafetyInterval = 15 * time.Second
+	// 合并调度信号的等待时间
+	dispatchCoalesceWindow = 200 * time.Millisecond
+)
+
 // TaskScheduler 任务调度器
 type TaskScheduler struct {
-	taskService   service.TaskServiceI
-	workerService workerSvc.WorkerServiceI
-	rabbitMQ      rabbitmq.RabbitMQService
+	taskService     service.TaskServiceI
+	workerService   workerSvc.WorkerServiceI
+	scheduleService service.TaskScheduleServiceI
+	workflowService service.TaskWorkflowServiceI
+	rabbitMQ        rabbitmq.RabbitMQService
+	policy          SchedulingPolicy
+	affinityWorkers string        // 上次平衡亲和关系时的在线工作节点
+	outboxWake      chan struct{} // 唤醒发件箱中继
 	running       bool
 	mutex         sync.Mutex
 	stopChan      chan struct{}
 }
 
 // NewTaskScheduler 创建任务调度器
-func NewTaskScheduler(taskService service.TaskServiceI, workerService workerSvc.WorkerServiceI, rabbitMQ rabbitmq.RabbitMQService) *TaskScheduler {
+func NewTaskScheduler(taskService service.TaskServiceI, workerService workerSvc.WorkerServiceI, scheduleService service.TaskScheduleServiceI, workflowService service.TaskWorkflowServiceI, rabbitMQ rabbitmq.RabbitMQService) *TaskScheduler {
+	// 调度策略配置错误时回退到优先级策略
+	policy, err := NewSchedulingPolicy(global.Config.Task.Scheduling)
+	if err != nil {
+		global.LOG.Error(fmt.Sprintf("Invalid scheduling policy, falling back to %s: %v", PolicyPriority, err))
+		policy, _ = NewSchedulingPolicy(config.TaskScheduling{AgingInterval: global.Config.Task.Scheduling.AgingInterval})
+	}
+	
 	return &TaskScheduler{
-		taskService:   taskService,
-		workerService: workerService,
-		rabbitMQ:      rabbitMQ,
+		taskService:     taskService,
+		workerService:   workerService,
+		scheduleService: scheduleService,
+		workflowService: workflowService,
+		rabbitMQ:        rabbitMQ,
+		policy:          policy,
+		outboxWake:      make(chan struct{}, 1),
 		running:       false,
 		stopChan:      make(chan struct{}),
 	}
@@ -44,10 +72,10 @@ func (s *TaskScheduler) Start() error {
 		return fmt.Errorf("task scheduler is already running")
 	}
 	
-	// 启动任务调度循环
-	go s.scheduleLoop()
+	// 参与领导者选举，仅领导者运行任务调度和超时检测循环
+	go s.leaderLoop()
 	
-	// 启动任务结果处理器
+	// 启动任务结果处理器，所有实例共同消费结果队列
 	err := s.startResultProcessor()
 	if err != nil {
 		return fmt.Errorf("failed to start result processor: %w", err)
@@ -73,26 +101,100 @@ func (s *TaskScheduler) Stop() {
 }
 
 // 任务调度循环
-func (s *TaskScheduler) scheduleLoop() {
-	ticker := time.NewTicker(15 * time.Second)
+// 任务创建、工作节点上线和任务结束时立即调度，定时器仅用于兜底和处理到期的周期计划、延时任务
+func (s *TaskScheduler) scheduleLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(dispatchSafetyInterval)
 	defer ticker.Stop()
 	
+	go s.listenDispatch(stop)
+	
+	signals := dispatch.Signals()
 	for {
 		select {
 		case <-ticker.C:
+			s.runDueSchedules()
 			s.schedulePendingTasks()
-		case <-s.stopChan:
+		case <-signals:
+			// 等待短暂时间合并突发的调度信号
+			select {
+			case <-time.After(dispatchCoalesceWindow):
+			case <-stop:
+				return
+			}
+			select {
+			case <-signals:
+			default:
+			}
+			s.schedulePendingTasks()
+		case <-stop:
 			return
 		}
 	}
 }
 
+// 接收其他实例发出的调度信号，连接断开后重新订阅
+func (s *TaskScheduler) listenDispatch(stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+	
+	for {
+		if err := dispatch.Listen(ctx); err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to listen for dispatch signals: %v", err))
+		}
+		select {
+		case <-time.After(leaderRetryInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// 为到期的周期计划生成任务
+func (s *TaskScheduler) runDueSchedules() {
+	created, err := s.scheduleService.RunDueSchedules(context.Background(), time.Now())
+	if err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to run due task schedules: %v", err))
+		return
+	}
+	
+	if created > 0 {
+		global.LOG.Info(fmt.Sprintf("Created %d tasks from due schedules", created))
+	}
+}
+
 // 调度待处理任务
 func (s *TaskScheduler) schedulePendingTasks() {
-	// 获取所有待处理的任务
-	var pendingTasks []model.Task
-	if err := global.DB.Where("status = ?", "pending").
-		Order("priority DESC, created_at ASC").
+	// 全局暂停期间不分配任务
+	if dispatchPaused() {
+		return
+	}
+	
+	// 获取所有在线工作节点，判断任务是否有能执行它的节点
+	onlineWorkers, err := loadOnlineWorkers()
+	if err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to fetch online workers: %v", err))
+		return
+	}
+	
+	// 工作节点加入或离开时重新平衡账号亲和关系
+	s.rebalanceAffinities(onlineWorkers)
+	
+	// 获取所有待处理的任务及其账号分组和租户
+	now := time.Now()
+	var pendingTasks []PendingTask
+	if err := global.DB.Table("tasks").
+		Select("tasks.*, accounts.account_group_id, accounts.created_by_user_id AS tenant_id").
+		Joins("LEFT JOIN accounts ON accounts.id = tasks.account_id").
+		Where("tasks.status = ? AND tasks.deleted_at IS NULL", "pending").
+		// 暂停调度的账号和账号分组的任务不分配，包括暂停后新建和重新排队的任务
+		Where("COALESCE(accounts.tasks_paused, FALSE) = FALSE").
+		Where("accounts.account_group_id IS NULL OR accounts.account_group_id NOT IN (?)", service.PausedAccountGroups()).
+		Where("tasks.next_retry_at IS NULL OR tasks.next_retry_at <= ?", now).
+		Where("tasks.run_at IS NULL OR tasks.run_at <= ?", now).
 		Find(&pendingTasks).Error; err != nil {
 		global.LOG.Error(fmt.Sprintf("Failed to fetch pending tasks: %v", err))
 		return
@@ -102,41 +204,91 @@ func (s *TaskScheduler) schedulePendingTasks() {
 		return
 	}
 	
-	// 获取可用的工作节点
+	// 按调度策略决定分配顺序
+	pendingTasks = s.policy.Order(pendingTasks, now)
+	
+	// 获取有空闲容量的工作节点
 	availableWorkers, err := s.workerService.GetAvailableWorkers(context.Background())
-	if err != nil || len(availableWorkers) == 0 {
-		global.LOG.Warn("No available workers for pending tasks")
+	if err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to fetch available workers: %v", err))
+		return
+	}
+	
+	// 获取账号并发上限
+	accountLimits, err := loadAccountConcurrency(pendingTasks)
+	if err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to load account concurrency limits: %v", err))
 		return
 	}
 	
-	// 轮询分配任务
+	// 获取账号亲和的工作节点
+	affinities, err := loadAffinities(pendingTasks)
+	if err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to load account affinities: %v", err))
+		return
+	}
+	
+	// 在具备所需标签的工作节点间轮询分配任务
 	workerIndex := 0
-	for _, task := range pendingTasks {
-		// 检查是否有可用工作节点
-		if len(availableWorkers) == 0 {
-			break
+	lockedAccounts := make(map[uint]bool)
+	for i := range pendingTasks {
+		task := &pendingTasks[i].Task
+		
+		// 没有在线节点具备所需标签的任务保持待处理，并标记为无法调度
+		tags := service.TaskRequiredTags(task)
+		capable := anyWorkerHasTags(onlineWorkers, tags)
+		setUnschedulable(task, !capable, tags)
+		if !capable {
+			continue
 		}
 		
-		// 选择工作节点
-		worker := availableWorkers[workerIndex]
-		workerIndex = (workerIndex + 1) % len(availableWorkers)
+		// 选择工作节点，优先选择账号亲和的节点，具备所需标签的节点都已满载时等待下一轮
+		idx, sticky := pickWorker(availableWorkers, workerIndex, tags, affinities[task.AccountID])
+		if idx < 0 {
+			continue
+		}
+		worker := availableWorkers[idx]
+		
+		// 账号的执行槽位已满时跳过，等待正在执行的任务结束
+		if lockedAccounts[task.AccountID] {
+			continue
+		}
+		locked, err := acquireAccountLock(task, accountLimits[task.AccountID])
+		if err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to lock account %d for task %s: %v", task.AccountID, task.TaskID, err))
+			continue
+		}
+		if !locked {
+			lockedAccounts[task.AccountID] = true
+			continue
+		}
 		
 		// 分配任务
-		if err := s.assignTaskToWorker(context.Background(), &task, worker.WorkerID); err != nil {
+		if err := s.assignTaskToWorker(context.Background(), task, worker.WorkerID); err != nil {
+			releaseAccountLock(task.AccountID, task.TaskID)
 			global.LOG.Error(fmt.Sprintf("Failed to assign task %s to worker %s: %v", 
 				task.TaskID, worker.WorkerID, err))
 			continue
 		}
 		
-		// 更新工作节点当前任务数
-		worker.CurrentTasks++
+		// 记录账号亲和的工作节点，账号的后续任务优先分配给该节点
+		recordAffinity(task.AccountID, worker.WorkerID)
+		affinities[task.AccountID] = worker.WorkerID
 		
-		// 如果工作节点任务数达到最大值，从可用列表移除
-		if worker.CurrentTasks >= worker.MaxTasks {
-			availableWorkers = append(availableWorkers[:workerIndex], availableWorkers[workerIndex+1:]...)
-			if workerIndex > 0 {
-				workerIndex--
-			}
+		// 更新工作节点当前任务数，达到最大值时从可用列表移除
+		// 选中亲和节点时不推进轮询位置
+		worker.CurrentTasks++
+		full := worker.CurrentTasks >= worker.MaxTasks
+		if full {
+			availableWorkers = append(availableWorkers[:idx], availableWorkers[idx+1:]...)
+		}
+		switch {
+		case !sticky && full:
+			workerIndex = idx
+		case !sticky:
+			workerIndex = idx + 1
+		case full && idx < workerIndex:
+			workerIndex--
 		}
 	}
 }
@@ -144,55 +296,59 @@ func (s *TaskScheduler) schedulePendingTasks() {
 // 分配任务给工作节点
 func (s *TaskScheduler) assignTaskToWorker(ctx context.Context, task *model.Task, workerID string) error {
 	// 创建任务分配记录
+	now := time.Now()
+	queuedAt := service.TaskReadyAt(task)
 	assignment := model.TaskAssignment{
 		TaskID:     task.TaskID,
 		WorkerID:   workerID,
 		Status:     "assigned",
-		AssignedAt: time.Now(),
+		AssignedAt: now,
 		Priority:   task.Priority,
 	}
 	
-	if err := global.DB.Create(&assignment).Error; err != nil {
-		return fmt.Errorf("failed to create task assignment: %w", err)
-	}
-	
-	// 更新任务状态
-	if err := global.DB.Model(task).Updates(map[string]interface{}{
-		"status": "assigned",
-	}).Error; err != nil {
-		return fmt.Errorf("failed to update task status: %w", err)
-	}
-	
-	// 更新工作节点当前任务数
-	if err := global.DB.Model(&model.Worker{}).
-		Where("worker_id = ?", workerID).
-		Update("current_tasks", global.DB.Raw("current_tasks + 1")).
-		Error; err != nil {
-		return fmt.Errorf("failed to update worker task count: %w", err)
-	}
-	
-	// 发送任务到队列
-	taskMessage := map[string]interface{}{
-		"task_id":    task.TaskID,
-		"task_type":  task.TaskType,
-		"account_id": task.AccountID,
-		"params":     task.Params,
-		"worker_id":  workerID,
-		"timeout":    task.TimeoutSec,
-		"created_at": task.CreatedAt,
-	}
-	
-	// 序列化任务消息
-	taskData, err := json.Marshal(taskMessage)
+	// 在同一事务中更新任务状态、创建分配记录、增加工作节点任务数并写入待发送消息
+	// 任务已被取消或分配时放弃本次分配
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := service.TransitionTaskStatus(tx, task, service.TaskStatusAssigned, service.TaskActorScheduler,
+			fmt.Sprintf("Assigned to worker %s", workerID), map[string]interface{}{
+				"attempt":              gorm.Expr("attempt + 1"),
+				"next_retry_at":        nil,
+				"progress":             0,
+				"progress_message":     "",
+				"unschedulable":        false,
+				"unschedulable_reason": "",
+				"queued_at":            queuedAt,
+				"assigned_at":          now,
+			}); err != nil {
+			return fmt.Errorf("failed to update task status: %w", err)
+		}
+		if err := tx.Create(&assignment).Error; err != nil {
+			return fmt.Errorf("failed to create task assignment: %w", err)
+		}
+		// 工作节点已被判定离线时放弃分配
+		result := tx.Model(&model.Worker{}).
+			Where("worker_id = ? AND status = ?", workerID, "online").
+			Update("current_tasks", gorm.Expr("current_tasks + 1"))
+		if result.Error != nil {
+			return fmt.Errorf("failed to update worker task count: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("worker %s is offline", workerID)
+		}
+		if err := enqueueTaskMessage(tx, task, workerID); err != nil {
+			return fmt.Errorf("failed to enqueue task message: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal task message: %w", err)
+		return err
 	}
+	service.PublishTaskEvent(service.NewTaskEvent(task, string(service.TaskStatusAssigned)))
 	
-	// 发送到RabbitMQ
-	if err := s.rabbitMQ.PublishTask(task.TaskType, taskData); err != nil {
-		return fmt.Errorf("failed to publish task: %w", err)
-	}
+	// 通知中继发送任务消息
+	s.wakeOutbox()
 	
+	service.AppendTaskLog(task.TaskID, workerID, service.TaskLogInfo, "Assigned to worker %s (attempt %d/%d)", workerID, task.Attempt+1, task.MaxAttempts)
 	global.LOG.Info(fmt.Sprintf("Task %s assigned to worker %s", task.TaskID, workerID))
 	return nil
 }
@@ -208,6 +364,11 @@ func (s *TaskScheduler) startResultProcessor() error {
 		return fmt.Errorf("failed to create task result consumer: %w", err)
 	}
 	
+	// 创建任务日志消费者
+	if err := s.rabbitMQ.CreateTaskLogConsumer(service.IngestTaskLogs); err != nil {
+		return fmt.Errorf("failed to create task log consumer: %w", err)
+	}
+	
 	global.LOG.Info("Task result processor started")
 	return nil
 }
@@ -216,52 +377,164 @@ func (s *TaskScheduler) startResultProcessor() error {
 func (s *TaskScheduler) processTaskResult(data []byte) error {
 	// 解析任务结果
 	var result struct {
+		Type        string                 `json:"type"`
 		TaskID      string                 `json:"task_id"`
 		AccountID   uint                   `json:"account_id"`
 		WorkerID    string                 `json:"worker_id"`
 		Status      string                 `json:"status"`
 		Result      map[string]interface{} `json:"result"`
 		Error       string                 `json:"error"`
+		ErrorClass  string                 `json:"error_class"`
 		CompletedAt time.Time              `json:"completed_at"`
 	}
 	
+	// 无法解析的消息重新投递也无法处理，记录后确认
 	if err := json.Unmarshal(data, &result); err != nil {
-		return fmt.Errorf("failed to unmarshal task result: %w", err)
+		global.LOG.Error(fmt.Sprintf("Dropping unparseable task result %s: %v", string(data), err))
+		return nil
 	}
 	
-	// 更新任务状态
-	status := service.TaskStatusCompleted
-	if result.Status == "failed" {
-		status = service.TaskStatusFailed
+	// 进度事件与结果共用结果交换机，按type区分
+	if result.Type == taskEventProgress {
+		return s.processTaskProgress(data)
 	}
 	
-	// 序列化结果数据
-	resultData, _ := json.Marshal(result.Result)
-	resultStr := string(resultData)
+	// 任务不存在或已被归档时确认消息，只有查询失败才重新投递
+	var task model.Task
+	if err := global.DB.Where("task_id = ?", result.TaskID).First(&task).Error; err == gorm.ErrRecordNotFound {
+		global.LOG.Warn(fmt.Sprintf("Dropping result of unknown or archived task %s from worker %s", result.TaskID, result.WorkerID))
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to find task: %w", err)
+	}
 	
-	// 更新任务状态
-	ctx := context.Background()
-	if err := s.taskService.UpdateTaskStatus(ctx, result.TaskID, status, resultStr, result.Error); err != nil {
-		return fmt.Errorf("failed to update task status: %w", err)
+	// 已超时或已取消的任务忽略迟到的结果，其工作节点槽位已被释放
+	if task.Status == "timeout" || task.Status == "canceled" {
+		global.LOG.Warn(fmt.Sprintf("Ignoring late result for %s task %s", task.Status, result.TaskID))
+		return nil
 	}
 	
-	// 更新任务分配记录
-	if err := global.DB.Model(&model.TaskAssignment{}).
-		Where("task_id = ? AND worker_id = ?", result.TaskID, result.WorkerID).
-		Updates(map[string]interface{}{
-			"status":       result.Status,
-			"completed_at": result.CompletedAt,
-		}).Error; err != nil {
-		return fmt.Errorf("failed to update task assignment: %w", err)
+	// 工作节点失联后任务已重新排队，忽略其迟到的结果
+	var assignment model.TaskAssignment
+	if err := global.DB.Where("task_id = ? AND worker_id = ? AND completed_at IS NULL", result.TaskID, result.WorkerID).
+		Order("id DESC").First(&assignment).Error; err == gorm.ErrRecordNotFound {
+		global.LOG.Warn(fmt.Sprintf("Ignoring result of task %s from worker %s without an open assignment", result.TaskID, result.WorkerID))
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to find task assignment: %w", err)
 	}
 	
-	// 更新工作节点当前任务数
-	if err := global.DB.Model(&model.Worker{}).
-		Where("worker_id = ?", result.WorkerID).
-		Update("current_tasks", global.DB.Raw("current_tasks - 1")).
-		Error; err != nil {
-		return fmt.Errorf("failed to update worker task count: %w", err)
+	// 记录本次尝试的执行结果
+	startedAt := assignment.AssignedAt
+	if task.StartedAt != nil {
+		startedAt = *task.StartedAt
+	}
+	completedAt := result.CompletedAt
+	record := model.TaskRecord{
+		TaskID:        result.TaskID,
+		WorkerID:      result.WorkerID,
+		Attempt:       task.Attempt,
+		Status:        result.Status,
+		Result:        result.Result,
+		ErrorMessage:  result.Error,
+		StartedAt:     startedAt,
+		CompletedAt:   &completedAt,
+		ExecutionTime: int(completedAt.Sub(startedAt).Milliseconds()),
 	}
+	service.ApplyTaskTimings(&record, &task)
+	
+	// 可重试的失败按退避时间重新排队，取消中的任务不再重试
+	retry := result.Status == "failed" && task.Status != "canceling" && IsRetryable(&task, result.ErrorClass)
+	status := service.TaskStatusCompleted
+	switch result.Status {
+	case "failed":
+		status = service.TaskStatusFailed
+	case "canceled":
+		// 工作节点确认取消
+		status = service.TaskStatusCanceled
+	}
+	
+	// 执行记录、任务状态、分配记录和工作节点任务数在同一事务中更新
+	// 事务失败时消息会重新投递，不会留下重复的执行记录
+	var retryAt time.Time
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		
+		if retry {
+			var err error
+			if retryAt, err = requeueForRetry(tx, &task, result.WorkerID, result.Error, time.Now()); err != nil {
+				return err
+			}
+		} else {
+			// 操作者为上报结果的工作节点
+			updates := map[string]interface{}{
+				"completed_at": time.Now(),
+			}
+			if result.Error != "" {
+				updates["error_message"] = result.Error
+			}
+			if err := service.TransitionTaskStatus(tx, &task, status, result.WorkerID, result.Error, updates); err != nil {
+				return err
+			}
+		}
+		
+		// 关闭任务分配记录
+		if err := tx.Model(&model.TaskAssignment{}).
+			Where("id = ?", assignment.ID).
+			Updates(map[string]interface{}{
+				"status":       result.Status,
+				"completed_at": result.CompletedAt,
+			}).Error; err != nil {
+			return err
+		}
+		
+		// 释放工作节点的任务槽位
+		return tx.Model(&model.Worker{}).
+			Where("worker_id = ? AND current_tasks > 0", result.WorkerID).
+			Update("current_tasks", gorm.Expr("current_tasks - 1")).
+			Error
+	})
+	if errors.Is(err, global.ErrorTaskStatusConflict) || errors.Is(err, global.ErrorInvalidTaskStatus) {
+		// 任务状态已被其他流程修改或结果与当前状态不符，重新投递也无法处理，确认消息
+		global.LOG.Warn(fmt.Sprintf("Ignoring %s result of %s task %s from worker %s: %v",
+			result.Status, task.Status, result.TaskID, result.WorkerID, err))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save task result: %w", err)
+	}
+	
+	if retry {
+		announceRetry(&task, result.Error, retryAt)
+	} else {
+		if result.Error != "" {
+			task.ErrorMessage = result.Error
+		}
+		service.PublishTaskEvent(service.NewTaskEvent(&task, string(status)))
+		switch status {
+		case service.TaskStatusFailed:
+			service.AppendTaskLog(result.TaskID, result.WorkerID, service.TaskLogError, "Failed on attempt %d/%d: %s", task.Attempt, task.MaxAttempts, result.Error)
+		case service.TaskStatusCanceled:
+			service.AppendTaskLog(result.TaskID, result.WorkerID, service.TaskLogInfo, "Cancellation confirmed by worker %s", result.WorkerID)
+		default:
+			service.AppendTaskLog(result.TaskID, result.WorkerID, service.TaskLogInfo, "Completed on attempt %d/%d", task.Attempt, task.MaxAttempts)
+		}
+		
+		// 释放或取消工作流中的下游任务
+		if task.WorkflowID != "" {
+			if err := s.workflowService.ResolveDependents(context.Background(), result.TaskID, status == service.TaskStatusCompleted); err != nil {
+				global.LOG.Error(fmt.Sprintf("Failed to resolve dependents of task %s: %v", result.TaskID, err))
+			}
+		}
+	}
+	
+	// 本次执行已结束，释放账号锁
+	releaseAccountLock(task.AccountID, task.TaskID)
+	
+	// 工作节点和账号空出容量，立即调度下一批任务
+	dispatch.Notify("task_finished")
 	
 	global.LOG.Info(fmt.Sprintf("Task %s processing completed with status: %s", result.TaskID, result.Status))
 	return nil
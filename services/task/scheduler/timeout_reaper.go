@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/dispatch"
+	"tg_manager_api/services/task/service"
+)
+
+// 超时检测间隔
+const timeoutCheckInterval = 30 * time.Second
+
+// timedOutTask 超时任务及其当前分配信息
+type timedOutTask struct {
+	model.Task
+	AssignmentID uint      `gorm:"column:assignment_id"`
+	WorkerID     string    `gorm:"column:worker_id"`
+	AssignedAt   time.Time `gorm:"column:assignment_assigned_at"`
+}
+
+// 超时检测循环
+func (s *TaskScheduler) timeoutLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(timeoutCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.reapTimedOutTasks()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// 回收执行超时的任务
+func (s *TaskScheduler) reapTimedOutTasks() {
+	// 以开始执行时间为准，未开始执行的任务以分配时间为准
+	var tasks []timedOutTask
+	if err := global.DB.Table("tasks").
+		Select("tasks.*, task_assignments.id AS assignment_id, task_assignments.worker_id, task_assignments.assigned_at AS assignment_assigned_at").
+		Joins("JOIN task_assignments ON task_assignments.task_id = tasks.task_id AND task_assignments.completed_at IS NULL AND task_assignments.deleted_at IS NULL").
+		Where("tasks.status IN ? AND tasks.deleted_at IS NULL", []string{"assigned", "processing", "canceling"}).
+		Where("DATE_ADD(COALESCE(tasks.started_at, task_assignments.assigned_at), INTERVAL tasks.timeout_sec SECOND) < ?", time.Now()).
+		Find(&tasks).Error; err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to fetch timed out tasks: %v", err))
+		return
+	}
+
+	for i := range tasks {
+		if err := s.timeoutTask(&tasks[i]); err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to time out task %s: %v", tasks[i].TaskID, err))
+		}
+	}
+
+	// 超时任务释放的工作节点和账号容量可立即使用
+	if len(tasks) > 0 {
+		dispatch.Notify("task_timeout")
+	}
+}
+
+// 将任务标记为超时，释放工作节点并通知其停止执行
+func (s *TaskScheduler) timeoutTask(task *timedOutTask) error {
+	now := time.Now()
+	startedAt := task.AssignedAt
+	if task.StartedAt != nil {
+		startedAt = *task.StartedAt
+	}
+	errorMsg := fmt.Sprintf("Task timed out after %d seconds", task.TimeoutSec)
+
+	// 仍有重试次数的任务重新排队，否则标记为超时
+	status := service.TaskStatusTimeout
+	retry := task.Status != string(service.TaskStatusCanceling) && IsRetryable(&task.Task, ErrorClassTimeout)
+	if task.Status == string(service.TaskStatusCanceling) {
+		// 工作节点未在超时前确认取消，直接完成取消
+		status = service.TaskStatusCanceled
+		errorMsg = "Task canceled by user, worker did not confirm before timeout"
+	}
+	newStatus := status
+	updates := map[string]interface{}{
+		"completed_at":  now,
+		"error_message": errorMsg,
+	}
+	if retry {
+		newStatus = service.TaskStatusPending
+		updates = map[string]interface{}{
+			"assigned_at":   nil,
+			"started_at":    nil,
+			"error_message": errorMsg,
+			"next_retry_at": nextRetryAt(&task.Task, now),
+		}
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		// 以当前状态为条件更新，避免覆盖同时到达的执行结果
+		if err := service.TransitionTaskStatus(tx, &task.Task, newStatus, service.TaskActorScheduler, errorMsg, updates); err != nil {
+			return err
+		}
+
+		// 关闭任务分配记录
+		if err := tx.Model(&model.TaskAssignment{}).
+			Where("id = ?", task.AssignmentID).
+			Updates(map[string]interface{}{
+				"status":       string(status),
+				"completed_at": now,
+			}).Error; err != nil {
+			return err
+		}
+
+		// 释放工作节点的任务槽位
+		if err := tx.Model(&model.Worker{}).
+			Where("worker_id = ? AND current_tasks > 0", task.WorkerID).
+			Update("current_tasks", gorm.Expr("current_tasks - 1")).
+			Error; err != nil {
+			return err
+		}
+
+		// 记录本次执行
+		record := model.TaskRecord{
+			TaskID:        task.TaskID,
+			WorkerID:      task.WorkerID,
+			Attempt:       task.Attempt,
+			Status:        string(status),
+			ErrorMessage:  errorMsg,
+			StartedAt:     startedAt,
+			CompletedAt:   &now,
+			ExecutionTime: int(now.Sub(startedAt).Milliseconds()),
+		}
+		service.ApplyTaskTimings(&record, &task.Task)
+		return tx.Create(&record).Error
+	})
+	if err == global.ErrorTaskStatusConflict {
+		// 任务已结束
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	releaseAccountLock(task.AccountID, task.TaskID)
+	task.ErrorMessage = errorMsg
+	service.PublishTaskEvent(service.NewTaskEvent(&task.Task, string(newStatus)))
+	if retry {
+		service.AppendTaskLog(task.TaskID, task.WorkerID, service.TaskLogWarn, "Attempt %d/%d timed out after %d seconds, retrying", task.Attempt, task.MaxAttempts, task.TimeoutSec)
+	} else {
+		service.AppendTaskLog(task.TaskID, task.WorkerID, service.TaskLogError, "%s", errorMsg)
+	}
+	
+	// 通知工作节点停止执行
+	cancelData, err := json.Marshal(map[string]interface{}{
+		"task_id":   task.TaskID,
+		"worker_id": task.WorkerID,
+		"reason":    status,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cancel message: %w", err)
+	}
+	if err := s.rabbitMQ.PublishTaskCancel(cancelData); err != nil {
+		return fmt.Errorf("failed to publish task cancel: %w", err)
+	}
+
+	// 不再重试的工作流任务按失败处理其下游任务
+	if !retry && task.WorkflowID != "" {
+		if err := s.workflowService.ResolveDependents(context.Background(), task.TaskID, false); err != nil {
+			global.LOG.Error(fmt.Sprintf("Failed to resolve dependents of task %s: %v", task.TaskID, err))
+		}
+	}
+
+	global.LOG.Warn(fmt.Sprintf("Task %s on worker %s timed out after %d seconds (attempt %d/%d, retry: %t)",
+		task.TaskID, task.WorkerID, task.TimeoutSec, task.Attempt, task.MaxAttempts, retry))
+	return nil
+}
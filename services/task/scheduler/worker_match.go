@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/service"
+)
+
+// 查询所有在线工作节点，用于判断任务是否有能执行它的节点
+func loadOnlineWorkers() ([]*model.Worker, error) {
+	var workers []*model.Worker
+	if err := global.DB.Select("worker_id", "tags").
+		Where("status = ?", "online").
+		Find(&workers).Error; err != nil {
+		return nil, err
+	}
+	return workers, nil
+}
+
+// 是否有工作节点具备全部所需标签
+func anyWorkerHasTags(workers []*model.Worker, tags []string) bool {
+	for _, worker := range workers {
+		if service.WorkerHasTags(worker, tags) {
+			return true
+		}
+	}
+	return false
+}
+
+// 从start开始轮询查找具备所需标签的工作节点，返回其下标，没有时返回-1
+func selectWorker(workers []*model.Worker, start int, tags []string) int {
+	for i := 0; i < len(workers); i++ {
+		idx := (start + i) % len(workers)
+		if service.WorkerHasTags(workers[idx], tags) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// 更新任务的无法调度标记，标记未变化时不写数据库
+func setUnschedulable(task *model.Task, unschedulable bool, tags []string) {
+	reason := ""
+	if unschedulable {
+		reason = fmt.Sprintf("No online worker has tags: %s", strings.Join(tags, ","))
+	}
+	if task.Unschedulable == unschedulable && task.UnschedulableReason == reason {
+		return
+	}
+
+	if err := global.DB.Model(&model.Task{}).
+		Where("task_id = ? AND status = ?", task.TaskID, service.TaskStatusPending).
+		Updates(map[string]interface{}{
+			"unschedulable":        unschedulable,
+			"unschedulable_reason": reason,
+		}).Error; err != nil {
+		global.LOG.Error(fmt.Sprintf("Failed to update schedulability of task %s: %v", task.TaskID, err))
+		return
+	}
+
+	task.Unschedulable = unschedulable
+	task.UnschedulableReason = reason
+	if unschedulable {
+		service.AppendTaskLog(task.TaskID, "", service.TaskLogWarn, "Unschedulable: %s", reason)
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tg_manager_api/config"
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// 默认每批归档的任务数
+const defaultArchiveBatchSize = 500
+
+// 默认归档的任务状态，即所有已结束状态
+var defaultArchiveStatuses = []string{
+	string(TaskStatusCompleted), string(TaskStatusFailed), string(TaskStatusCanceled),
+	string(TaskStatusTimeout), string(TaskStatusSkipped),
+}
+
+// 工作流已结束的汇总状态
+var finishedWorkflowStatuses = []string{"completed", "failed", "canceled"}
+
+// ArchivedTask 归档任务的完整数据
+type ArchivedTask struct {
+	Task        *model.Task                `json:"task"`        // 任务
+	Assignments []*model.TaskAssignment    `json:"assignments"` // 分配记录
+	Records     []*model.TaskRecord        `json:"records"`     // 执行记录
+	History     []*model.TaskStatusHistory `json:"history"`     // 状态变化历史
+	Logs        []*model.TaskLog           `json:"logs"`        // 任务日志
+	ArchivedAt  time.Time                  `json:"archived_at"` // 归档时间
+}
+
+// ArchiveBatchSize 每批归档的任务数
+func ArchiveBatchSize(cfg config.TaskRetention) int {
+	if cfg.BatchSize > 0 {
+		return cfg.BatchSize
+	}
+	return defaultArchiveBatchSize
+}
+
+// ArchiveStatuses 归档的任务状态，配置中的非终态会被忽略，避免归档仍在处理的任务
+func ArchiveStatuses(cfg config.TaskRetention) []string {
+	if len(cfg.Statuses) == 0 {
+		return defaultArchiveStatuses
+	}
+
+	statuses := make([]string, 0, len(cfg.Statuses))
+	for _, status := range cfg.Statuses {
+		if IsFinishedStatus(status) {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// ArchiveTasks 将一批结束时间早于cutoff的任务及其关联记录移入归档表，返回归档的任务数
+// 所属工作流尚未结束的任务暂不归档，避免工作流汇总状态缺少任务
+func ArchiveTasks(ctx context.Context, cutoff time.Time, statuses []string, batchSize int) (int, error) {
+	if len(statuses) == 0 {
+		return 0, nil
+	}
+
+	var tasks []*model.Task
+	if err := global.DB.WithContext(ctx).
+		Where("status IN ?", statuses).
+		Where("completed_at < ? OR (completed_at IS NULL AND updated_at < ?)", cutoff, cutoff).
+		Where("workflow_id = '' OR workflow_id IN (?)", global.DB.Model(&model.TaskWorkflow{}).
+			Select("workflow_id").Where("status IN ?", finishedWorkflowStatuses)).
+		Order("id ASC").
+		Limit(batchSize).
+		Find(&tasks).Error; err != nil {
+		return 0, err
+	}
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+
+	taskIDs := make([]string, 0, len(tasks))
+	archived := make(map[string]*ArchivedTask, len(tasks))
+	now := time.Now()
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.TaskID)
+		archived[task.TaskID] = &ArchivedTask{Task: task, ArchivedAt: now}
+	}
+
+	// 加载关联记录
+	var assignments []*model.TaskAssignment
+	if err := global.DB.WithContext(ctx).Where("task_id IN ?", taskIDs).Order("id ASC").Find(&assignments).Error; err != nil {
+		return 0, err
+	}
+	for _, assignment := range assignments {
+		archived[assignment.TaskID].Assignments = append(archived[assignment.TaskID].Assignments, assignment)
+	}
+
+	var records []*model.TaskRecord
+	if err := global.DB.WithContext(ctx).Where("task_id IN ?", taskIDs).Order("id ASC").Find(&records).Error; err != nil {
+		return 0, err
+	}
+	for _, record := range records {
+		archived[record.TaskID].Records = append(archived[record.TaskID].Records, record)
+	}
+
+	var history []*model.TaskStatusHistory
+	if err := global.DB.WithContext(ctx).Where("task_id IN ?", taskIDs).Order("id ASC").Find(&history).Error; err != nil {
+		return 0, err
+	}
+	for _, item := range history {
+		archived[item.TaskID].History = append(archived[item.TaskID].History, item)
+	}
+
+	var logs []*model.TaskLog
+	if err := global.DB.WithContext(ctx).Where("task_id IN ?", taskIDs).Order("id ASC").Find(&logs).Error; err != nil {
+		return 0, err
+	}
+	for _, log := range logs {
+		archived[log.TaskID].Logs = append(archived[log.TaskID].Logs, log)
+	}
+
+	rows := make([]*model.TaskArchive, 0, len(tasks))
+	for _, task := range tasks {
+		data, err := json.Marshal(archived[task.TaskID])
+		if err != nil {
+			return 0, err
+		}
+		rows = append(rows, &model.TaskArchive{
+			TaskID:      task.TaskID,
+			TaskType:    task.TaskType,
+			AccountID:   task.AccountID,
+			Status:      task.Status,
+			CompletedAt: task.CompletedAt,
+			ArchivedAt:  now,
+			Data:        string(data),
+		})
+	}
+
+	// 写入归档并物理删除在线记录，之前中断的批次已写入的归档直接跳过
+	err := global.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, batchInsertSize).Error; err != nil {
+			return err
+		}
+		for _, table := range []interface{}{
+			&model.TaskLog{}, &model.TaskStatusHistory{}, &model.TaskRecord{}, &model.TaskAssignment{}, &model.Task{},
+		} {
+			if err := tx.Unscoped().Where("task_id IN ?", taskIDs).Delete(table).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(tasks), nil
+}
+
+// GetArchivedTask 按任务ID查询已归档的任务
+func GetArchivedTask(ctx context.Context, taskID string) (*ArchivedTask, error) {
+	var row model.TaskArchive
+	if err := global.DB.WithContext(ctx).Where("task_id = ?", taskID).First(&row).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, global.ErrorTaskNotFound
+		}
+		return nil, err
+	}
+
+	var archived ArchivedTask
+	if err := json.Unmarshal([]byte(row.Data), &archived); err != nil {
+		return nil, err
+	}
+	return &archived, nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/dispatch"
+)
+
+// 批量创建时每次写入的任务数
+const batchInsertSize = 200
+
+// TaskBatchSpec 批量任务定义
+type TaskBatchSpec struct {
+	TaskType        string                 // 任务类型
+	AccountGroupID  uint                   // 目标账号分组ID
+	AccountIDs      []uint                 // 目标账号ID列表
+	AccountStatuses []string               // 允许的账号状态，默认仅ACTIVE
+	Params          map[string]interface{} // 任务参数模板
+	Options         *CreateTaskOptions     // 任务可选参数
+}
+
+// TaskBatchProgress 批量任务进度
+type TaskBatchProgress struct {
+	Batch        *model.TaskBatch `json:"batch"`         // 批次信息
+	Status       string           `json:"status"`        // 汇总状态
+	StatusCounts map[string]int   `json:"status_counts"` // 各状态任务数
+	Finished     int              `json:"finished"`      // 已结束的任务数
+	Progress     int              `json:"progress"`      // 完成百分比
+}
+
+// TaskBatchServiceI 批量任务服务接口
+type TaskBatchServiceI interface {
+	// 为账号分组或账号列表批量创建任务
+	CreateBatch(ctx context.Context, spec *TaskBatchSpec) (*model.TaskBatch, error)
+
+	// 获取批量任务进度
+	GetBatchProgress(ctx context.Context, batchID string) (*TaskBatchProgress, error)
+}
+
+// NewTaskBatchService 创建批量任务服务实例
+func NewTaskBatchService() TaskBatchServiceI {
+	return &taskBatchService{}
+}
+
+// taskBatchService 批量任务服务实现
+type taskBatchService struct{}
+
+// UniqueAccountIDs 按原顺序去除重复的账号ID
+func UniqueAccountIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// CreateBatch 为账号分组或账号列表批量创建任务
+// 仅为状态符合要求的账号创建任务，所有任务在同一事务中写入
+func (s *taskBatchService) CreateBatch(ctx context.Context, spec *TaskBatchSpec) (*model.TaskBatch, error) {
+	if spec.AccountGroupID == 0 && len(spec.AccountIDs) == 0 {
+		return nil, fmt.Errorf("account_group_id or account_ids is required")
+	}
+	if err := ValidateTaskParams(spec.TaskType, spec.Params); err != nil {
+		return nil, err
+	}
+	// 重复的账号只创建一个任务，也不计入跳过数
+	accountIDs := UniqueAccountIDs(spec.AccountIDs)
+
+	statuses := spec.AccountStatuses
+	if len(statuses) == 0 {
+		statuses = []string{"ACTIVE"}
+	}
+
+	// 查询目标账号
+	query := global.DB.Model(&model.Account{})
+	if spec.AccountGroupID > 0 {
+		query = query.Where("account_group_id = ?", spec.AccountGroupID)
+	}
+	if len(accountIDs) > 0 {
+		query = query.Where("id IN ?", accountIDs)
+	}
+
+	var accounts []model.Account
+	if err := query.Select("id", "status", "account_group_id", "account_level").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		allowed[status] = true
+	}
+
+	batch := &model.TaskBatch{
+		BatchID:        fmt.Sprintf("batch_%s", uuid.New().String()),
+		TaskType:       spec.TaskType,
+		AccountGroupID: spec.AccountGroupID,
+		Params:         spec.Params,
+	}
+
+	tasks := make([]*model.Task, 0, len(accounts))
+	eligible := make(map[uint]*model.Account, len(accounts))
+	for i := range accounts {
+		account := &accounts[i]
+		if !allowed[account.Status] {
+			batch.SkippedCount++
+			continue
+		}
+
+		task := newTask(spec.TaskType, account.ID, spec.Params)
+		spec.Options.apply(task)
+		task.BatchID = batch.BatchID
+		tasks = append(tasks, task)
+		eligible[account.ID] = account
+	}
+	// 账号列表中不存在的账号也计入跳过数
+	if len(accountIDs) > 0 && spec.AccountGroupID == 0 {
+		batch.SkippedCount += len(accountIDs) - len(accounts)
+	}
+
+	if len(tasks) == 0 {
+		return nil, global.ErrorNoEligibleAccount
+	}
+	batch.TotalCount = len(tasks)
+
+	// 暂停调度的账号的任务创建为暂停状态
+	if err := pauseNewTasks(tasks); err != nil {
+		return nil, err
+	}
+
+	// 占用各账号的任务配额，超出配额的账号的任务推迟执行
+	deferred, err := reserveTaskQuotas(ctx, tasks, eligible)
+	if err != nil {
+		return nil, err
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if err := tx.CreateInBatches(tasks, batchInsertSize).Error; err != nil {
+			return err
+		}
+
+		histories := make([]model.TaskStatusHistory, 0, len(tasks))
+		for _, task := range tasks {
+			histories = append(histories, newCreationHistory(task, TaskActorUser, "Created by batch "+batch.BatchID))
+		}
+		return tx.CreateInBatches(histories, batchInsertSize).Error
+	})
+	if err != nil {
+		releaseTaskQuotas(ctx, tasks)
+		return nil, err
+	}
+	for _, task := range deferred {
+		logQuotaDeferred(task)
+	}
+
+	dispatch.Notify("batch_created")
+
+	return batch, nil
+}
+
+// GetBatchProgress 获取批量任务进度，按子任务状态汇总
+func (s *taskBatchService) GetBatchProgress(ctx context.Context, batchID string) (*TaskBatchProgress, error) {
+	var batch model.TaskBatch
+	if err := global.DB.Where("batch_id = ?", batchID).First(&batch).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, global.ErrorBatchNotFound
+		}
+		return nil, err
+	}
+
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	if err := global.DB.Model(&model.Task{}).
+		Select("status, COUNT(*) AS count").
+		Where("batch_id = ?", batchID).
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	total, finished := 0, 0
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+		total += row.Count
+		if IsFinishedStatus(row.Status) {
+			finished += row.Count
+		}
+	}
+
+	progress := 0
+	if total > 0 {
+		progress = finished * 100 / total
+	}
+
+	return &TaskBatchProgress{
+		Batch:        &batch,
+		Status:       aggregateTaskStatus(counts),
+		StatusCounts: counts,
+		Finished:     finished,
+		Progress:     progress,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// 任务事件的Redis发布订阅频道，所有API实例共享
+const taskEventChannel = "task:events"
+
+// TaskEvent 任务状态或进度变化事件
+type TaskEvent struct {
+	TaskID          string    `json:"task_id"`          // 任务ID
+	AccountID       uint      `json:"account_id"`       // 关联的账号ID
+	Status          string    `json:"status"`           // 任务状态
+	Progress        int       `json:"progress"`         // 执行进度(0-100)
+	ProgressMessage string    `json:"progress_message"` // 进度说明
+	ErrorMessage    string    `json:"error_message"`    // 错误信息
+	Time            time.Time `json:"time"`             // 事件时间
+}
+
+// NewTaskEvent 根据任务当前信息创建事件，status为任务的新状态
+func NewTaskEvent(task *model.Task, status string) *TaskEvent {
+	return &TaskEvent{
+		TaskID:          task.TaskID,
+		AccountID:       task.AccountID,
+		Status:          status,
+		Progress:        task.Progress,
+		ProgressMessage: task.ProgressMessage,
+		ErrorMessage:    task.ErrorMessage,
+		Time:            time.Now(),
+	}
+}
+
+// PublishTaskEvent 发布任务事件，发布失败仅记录日志，不影响任务处理
+func PublishTaskEvent(event *TaskEvent) {
+	if global.Redis == nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		global.Logger.Warn("序列化任务事件失败", zap.String("task_id", event.TaskID), zap.Error(err))
+		return
+	}
+
+	if err := global.Redis.Publish(context.Background(), taskEventChannel, data).Err(); err != nil {
+		global.Logger.Warn("发布任务事件失败", zap.String("task_id", event.TaskID), zap.Error(err))
+	}
+}
+
+// SubscribeTaskEvents 订阅任务事件，仅返回match为true的事件，ctx结束后关闭通道
+// 任务事件经Redis在实例间分发，未配置Redis时返回ErrorEventsUnavailable
+func SubscribeTaskEvents(ctx context.Context, match func(event *TaskEvent) bool) (<-chan *TaskEvent, error) {
+	if global.Redis == nil {
+		return nil, global.ErrorEventsUnavailable
+	}
+
+	pubsub := global.Redis.Subscribe(ctx, taskEventChannel)
+
+	// 等待订阅生效，避免丢失订阅后立即发布的事件
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	events := make(chan *TaskEvent, 16)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event TaskEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					continue
+				}
+				if match != nil && !match(&event) {
+					continue
+				}
+
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
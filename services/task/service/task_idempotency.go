@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"tg_manager_api/global"
+)
+
+// 幂等键在Redis中的前缀
+const idempotencyKeyPrefix = "task:idempotency:"
+
+// 默认幂等键保留时间
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// 等待并发的同键请求完成的最长时间
+const idempotencyWaitTimeout = 3 * time.Second
+
+// idempotencyRecord 幂等键对应的请求摘要和已创建的任务ID
+type idempotencyRecord struct {
+	PayloadHash string `json:"payload_hash"` // 请求内容摘要
+	TaskID      string `json:"task_id"`      // 已创建的任务ID，为空表示创建中
+}
+
+// 获取幂等键保留时间
+func idempotencyTTL() time.Duration {
+	if ttl := global.Config.Task.IdempotencyTTL; ttl > 0 {
+		return time.Duration(ttl) * time.Second
+	}
+	return defaultIdempotencyTTL
+}
+
+// 计算创建任务请求的摘要，用于判断重复请求的内容是否一致
+func idempotencyPayloadHash(taskType string, accountID uint, params map[string]interface{}, opts *CreateTaskOptions) (string, error) {
+	payload := map[string]interface{}{
+		"task_type":  taskType,
+		"account_id": accountID,
+		"params":     params,
+	}
+	if opts != nil {
+		options := *opts
+		options.IdempotencyKey = ""
+		payload["options"] = options
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// claimIdempotencyKey 占用幂等键
+// 返回空字符串表示占用成功，调用方应创建任务；否则返回该键已创建的任务ID
+// 键已被内容不同的请求使用时返回ErrorTaskAlreadyExist
+func claimIdempotencyKey(ctx context.Context, key, payloadHash string) (string, error) {
+	redisKey := idempotencyKeyPrefix + key
+	claim, err := json.Marshal(idempotencyRecord{PayloadHash: payloadHash})
+	if err != nil {
+		return "", err
+	}
+
+	ok, err := global.Redis.SetNX(ctx, redisKey, claim, idempotencyTTL()).Result()
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return "", nil
+	}
+
+	// 键已存在，等待并发请求完成任务创建
+	deadline := time.Now().Add(idempotencyWaitTimeout)
+	for {
+		data, err := global.Redis.Get(ctx, redisKey).Bytes()
+		if err == redis.Nil {
+			// 之前的请求创建失败并释放了键，重新占用
+			return claimIdempotencyKey(ctx, key, payloadHash)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		var record idempotencyRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return "", err
+		}
+		if record.PayloadHash != payloadHash {
+			return "", fmt.Errorf("%w: idempotency key %q was used with a different payload", global.ErrorTaskAlreadyExist, key)
+		}
+		if record.TaskID != "" {
+			return record.TaskID, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("%w: request with idempotency key %q is still in progress", global.ErrorTaskAlreadyExist, key)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// completeIdempotencyKey 记录幂等键对应的任务ID
+func completeIdempotencyKey(ctx context.Context, key, payloadHash, taskID string) error {
+	data, err := json.Marshal(idempotencyRecord{PayloadHash: payloadHash, TaskID: taskID})
+	if err != nil {
+		return err
+	}
+	return global.Redis.Set(ctx, idempotencyKeyPrefix+key, data, idempotencyTTL()).Err()
+}
+
+// releaseIdempotencyKey 任务创建失败时释放幂等键，允许客户端重试
+func releaseIdempotencyKey(ctx context.Context, key string) {
+	global.Redis.Del(ctx, idempotencyKeyPrefix+key)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// 任务日志级别
+const (
+	TaskLogDebug = "debug"
+	TaskLogInfo  = "info"
+	TaskLogWarn  = "warn"
+	TaskLogError = "error"
+)
+
+// 日志级别由低到高排列，用于按最低级别过滤
+var taskLogLevels = []string{TaskLogDebug, TaskLogInfo, TaskLogWarn, TaskLogError}
+
+// AppendTaskLog 记录一条任务日志，写入失败仅记录系统日志，不影响任务处理
+func AppendTaskLog(taskID, workerID, level, format string, args ...interface{}) {
+	entry := model.TaskLog{
+		TaskID:   taskID,
+		WorkerID: workerID,
+		Level:    level,
+		Message:  fmt.Sprintf(format, args...),
+		LoggedAt: time.Now(),
+	}
+	if err := global.DB.Create(&entry).Error; err != nil {
+		global.Logger.Warn("写入任务日志失败", zap.String("task_id", taskID), zap.Error(err))
+	}
+}
+
+// IngestTaskLogs 保存工作节点通过消息队列上报的日志
+// 消息可以是单条日志，也可以是日志数组
+// 无法解析的消息和不存在或已归档任务的日志记录后丢弃，只有写入失败时返回错误重新投递
+func IngestTaskLogs(data []byte) error {
+	type workerLog struct {
+		TaskID    string    `json:"task_id"`
+		WorkerID  string    `json:"worker_id"`
+		Level     string    `json:"level"`
+		Message   string    `json:"message"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
+	var logs []workerLog
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &logs); err != nil {
+			global.Logger.Warn("丢弃无法解析的任务日志", zap.ByteString("data", data), zap.Error(err))
+			return nil
+		}
+	} else {
+		var log workerLog
+		if err := json.Unmarshal(data, &log); err != nil {
+			global.Logger.Warn("丢弃无法解析的任务日志", zap.ByteString("data", data), zap.Error(err))
+			return nil
+		}
+		logs = append(logs, log)
+	}
+
+	taskIDs := make([]string, 0, len(logs))
+	for _, log := range logs {
+		if log.TaskID != "" {
+			taskIDs = append(taskIDs, log.TaskID)
+		}
+	}
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	var existing []string
+	if err := global.DB.Model(&model.Task{}).Where("task_id IN ?", taskIDs).Pluck("task_id", &existing).Error; err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, taskID := range existing {
+		known[taskID] = true
+	}
+
+	entries := make([]model.TaskLog, 0, len(logs))
+	for _, log := range logs {
+		if log.TaskID == "" {
+			continue
+		}
+		if !known[log.TaskID] {
+			global.Logger.Warn("丢弃不存在任务的日志", zap.String("task_id", log.TaskID), zap.String("worker_id", log.WorkerID))
+			continue
+		}
+		entry := model.TaskLog{
+			TaskID:   log.TaskID,
+			WorkerID: log.WorkerID,
+			Level:    normalizeTaskLogLevel(log.Level),
+			Message:  log.Message,
+			LoggedAt: log.Timestamp,
+		}
+		if entry.LoggedAt.IsZero() {
+			entry.LoggedAt = time.Now()
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return global.DB.Create(&entries).Error
+}
+
+// GetTaskLogs 获取任务日志，level不为空时仅返回不低于该级别的日志
+func (s *taskServiceImpl) GetTaskLogs(ctx context.Context, taskID, level string, page, pageSize int) ([]*model.TaskLog, int64, error) {
+	query := global.DB.Model(&model.TaskLog{}).Where("task_id = ?", taskID)
+	if level != "" {
+		query = query.Where("level IN ?", taskLogLevelsFrom(normalizeTaskLogLevel(level)))
+	}
+
+	var logs []*model.TaskLog
+	var total int64
+
+	// 获取总记录数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询，按日志时间顺序返回
+	offset := (page - 1) * pageSize
+	if err := query.Order("logged_at ASC, id ASC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// 统一日志级别写法，未知级别按info处理
+func normalizeTaskLogLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	switch level {
+	case "warning":
+		return TaskLogWarn
+	case "critical", "fatal":
+		return TaskLogError
+	}
+	for _, known := range taskLogLevels {
+		if level == known {
+			return level
+		}
+	}
+	return TaskLogInfo
+}
+
+// 返回不低于指定级别的所有日志级别
+func taskLogLevelsFrom(level string) []string {
+	for i, known := range taskLogLevels {
+		if known == level {
+			return taskLogLevels[i:]
+		}
+	}
+	return taskLogLevels
+}
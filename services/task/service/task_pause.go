@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/dispatch"
+)
+
+// TaskPauseScope 批量暂停或恢复任务的范围，账号和账号分组至少指定一个
+type TaskPauseScope struct {
+	AccountID      uint // 账号ID
+	AccountGroupID uint // 账号分组ID
+}
+
+// PauseTask 暂停等待调度或等待上游的任务，已分配的任务不受影响
+// 暂停不修改任务的优先级和创建时间，恢复后任务回到原来的队列位置
+func (s *taskServiceImpl) PauseTask(ctx context.Context, taskID string) error {
+	task, err := findTask(taskID)
+	if err != nil {
+		return err
+	}
+	return pauseTask(task)
+}
+
+// ResumeTask 恢复已暂停的任务，上游任务尚未全部完成的工作流任务恢复为等待上游状态
+func (s *taskServiceImpl) ResumeTask(ctx context.Context, taskID string) error {
+	task, err := findTask(taskID)
+	if err != nil {
+		return err
+	}
+	if err := resumeTask(task); err != nil {
+		return err
+	}
+
+	dispatch.Notify("task_resumed")
+	return nil
+}
+
+// PauseTasks 暂停账号或账号分组下所有等待调度的任务
+// 同时记录暂停标记，恢复前调度器不分配其任务，新建和重新排队的任务也不会被分配
+func (s *taskServiceImpl) PauseTasks(ctx context.Context, scope TaskPauseScope) (int, error) {
+	if err := setScopePaused(scope, true); err != nil {
+		return 0, err
+	}
+
+	tasks, err := findScopedTasks(scope, TaskStatusPending)
+	if err != nil {
+		return 0, err
+	}
+
+	paused := 0
+	for i := range tasks {
+		// 任务已被分配或取消时跳过
+		if err := pauseTask(&tasks[i]); err == nil {
+			paused++
+		} else if !errors.Is(err, global.ErrorInvalidTaskStatus) {
+			return paused, err
+		}
+	}
+	return paused, nil
+}
+
+// ResumeTasks 恢复账号或账号分组下所有已暂停的任务
+// 账号本身或其所在分组仍处于暂停状态时，其任务保持暂停
+func (s *taskServiceImpl) ResumeTasks(ctx context.Context, scope TaskPauseScope) (int, error) {
+	if err := setScopePaused(scope, false); err != nil {
+		return 0, err
+	}
+
+	tasks, err := findScopedTasks(scope, TaskStatusPaused)
+	if err != nil {
+		return 0, err
+	}
+
+	accountIDs := make([]uint, 0, len(tasks))
+	for i := range tasks {
+		accountIDs = append(accountIDs, tasks[i].AccountID)
+	}
+	stillPaused, err := pausedAccounts(accountIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	resumed := 0
+	for i := range tasks {
+		if stillPaused[tasks[i].AccountID] {
+			continue
+		}
+		if err := resumeTask(&tasks[i]); err == nil {
+			resumed++
+		} else if !errors.Is(err, global.ErrorInvalidTaskStatus) {
+			return resumed, err
+		}
+	}
+
+	if resumed > 0 {
+		dispatch.Notify("task_resumed")
+	}
+	return resumed, nil
+}
+
+// 查找任务
+func findTask(taskID string) (*model.Task, error) {
+	var task model.Task
+	if err := global.DB.Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, global.ErrorTaskNotFound
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// PausedAccountGroups 暂停调度的账号分组ID子查询
+func PausedAccountGroups() *gorm.DB {
+	return global.DB.Model(&model.AccountGroup{}).Select("id").Where("tasks_paused = ?", true)
+}
+
+// 记录账号或账号分组的暂停标记，同时指定两者时仅标记账号
+func setScopePaused(scope TaskPauseScope, paused bool) error {
+	switch {
+	case scope.AccountID > 0:
+		return global.DB.Model(&model.Account{}).Where("id = ?", scope.AccountID).Update("tasks_paused", paused).Error
+	case scope.AccountGroupID > 0:
+		return global.DB.Model(&model.AccountGroup{}).Where("id = ?", scope.AccountGroupID).Update("tasks_paused", paused).Error
+	default:
+		return global.ErrorInvalidTaskScope
+	}
+}
+
+// 返回任务被暂停的账号，账号本身或其所在分组被暂停时均视为暂停
+func pausedAccounts(accountIDs []uint) (map[uint]bool, error) {
+	paused := make(map[uint]bool)
+	if len(accountIDs) == 0 {
+		return paused, nil
+	}
+
+	var ids []uint
+	if err := global.DB.Model(&model.Account{}).
+		Where("id IN ?", accountIDs).
+		Where("tasks_paused = ? OR account_group_id IN (?)", true, PausedAccountGroups()).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		paused[id] = true
+	}
+	return paused, nil
+}
+
+// 账号的任务被暂停时将新任务创建为暂停状态
+func pauseNewTasks(tasks []*model.Task) error {
+	accountIDs := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		accountIDs = append(accountIDs, task.AccountID)
+	}
+	paused, err := pausedAccounts(accountIDs)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if paused[task.AccountID] && task.Status == string(TaskStatusPending) {
+			task.Status = string(TaskStatusPaused)
+		}
+	}
+	return nil
+}
+
+// 查找范围内指定状态的任务
+func findScopedTasks(scope TaskPauseScope, status TaskStatus) ([]model.Task, error) {
+	if scope.AccountID == 0 && scope.AccountGroupID == 0 {
+		return nil, global.ErrorInvalidTaskScope
+	}
+
+	db := global.DB.Where("status = ?", status)
+	if scope.AccountID > 0 {
+		db = db.Where("account_id = ?", scope.AccountID)
+	}
+	if scope.AccountGroupID > 0 {
+		db = db.Where("account_id IN (?)", global.DB.Model(&model.Account{}).
+			Select("id").Where("account_group_id = ?", scope.AccountGroupID))
+	}
+
+	var tasks []model.Task
+	if err := db.Order("id ASC").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// 将任务转换为暂停状态，任务不处于等待调度或等待上游状态时返回ErrorInvalidTaskStatus
+func pauseTask(task *model.Task) error {
+	err := TransitionTaskStatus(global.DB, task, TaskStatusPaused, TaskActorUser, "Task paused by user", nil)
+	if err == global.ErrorTaskStatusConflict {
+		// 任务已被调度器分配
+		return global.ErrorInvalidTaskStatus
+	}
+	if err != nil {
+		return err
+	}
+
+	PublishTaskEvent(NewTaskEvent(task, string(TaskStatusPaused)))
+	AppendTaskLog(task.TaskID, "", TaskLogInfo, "Paused by user")
+	return nil
+}
+
+// ResumeStatus 暂停的任务恢复后的状态，上游任务尚未全部完成时回到等待上游状态
+func ResumeStatus(unfinishedParents int64) TaskStatus {
+	if unfinishedParents > 0 {
+		return TaskStatusBlocked
+	}
+	return TaskStatusPending
+}
+
+// 将暂停的任务恢复为等待调度状态，工作流中上游任务尚未全部完成的任务恢复为等待上游状态
+func resumeTask(task *model.Task) error {
+	var unfinished int64
+	if task.WorkflowID != "" {
+		var err error
+		if unfinished, err = countUnfinishedParents(task.TaskID); err != nil {
+			return err
+		}
+	}
+
+	status := ResumeStatus(unfinished)
+	err := TransitionTaskStatus(global.DB, task, status, TaskActorUser, "Task resumed by user", nil)
+	if err == global.ErrorTaskStatusConflict {
+		// 任务已被取消
+		return global.ErrorInvalidTaskStatus
+	}
+	if err != nil {
+		return err
+	}
+
+	PublishTaskEvent(NewTaskEvent(task, string(status)))
+	AppendTaskLog(task.TaskID, "", TaskLogInfo, "Resumed by user as %s", status)
+
+	// 上游任务在统计后完成时不会再释放该任务，需要重新检查
+	if status == TaskStatusBlocked {
+		if _, err := releaseChild(task.TaskID, "Upstream tasks completed"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
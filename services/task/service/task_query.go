@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// 任务列表排序字段
+const (
+	TaskSortCreatedAt   = "created_at"   // 按创建时间排序
+	TaskSortPriority    = "priority"     // 按优先级排序
+	TaskSortCompletedAt = "completed_at" // 按完成时间排序，仅返回已有完成时间的任务
+)
+
+// 排序字段对应的数据库列
+// 创建时间使用自增ID排序，与创建顺序一致，且可直接利用二级索引中隐含的主键
+var taskSortColumns = map[string]string{
+	TaskSortCreatedAt:   "id",
+	TaskSortPriority:    "priority",
+	TaskSortCompletedAt: "completed_at",
+}
+
+// TaskQuery 任务列表查询条件
+// Cursor为nil时使用页码分页，否则使用游标分页，空游标表示第一页
+type TaskQuery struct {
+	Statuses       []string   // 任务状态，多个状态为或关系
+	TaskType       string     // 任务类型
+	AccountID      uint       // 账号ID
+	AccountGroupID uint       // 账号分组ID
+	WorkerID       string     // 执行过该任务的工作节点ID
+	MinPriority    *int       // 最低优先级
+	MaxPriority    *int       // 最高优先级
+	CreatedFrom    *time.Time // 创建时间下限
+	CreatedTo      *time.Time // 创建时间上限
+	CompletedFrom  *time.Time // 完成时间下限
+	CompletedTo    *time.Time // 完成时间上限
+
+	SortBy   string  // 排序字段，默认created_at
+	SortDesc bool    // 是否降序
+	Cursor   *string // 游标，来自上一页结果的NextCursor
+	Page     int     // 页码，游标分页时忽略
+	PageSize int     // 每页数量
+
+	cursor *taskCursor
+}
+
+// TaskQueryResult 任务列表查询结果
+type TaskQueryResult struct {
+	List       []*model.Task // 任务列表
+	Total      int64         // 总数，游标分页时不统计
+	NextCursor string        // 下一页游标，为空表示没有更多数据
+}
+
+// taskCursor 游标内容，记录上一页最后一条记录的排序值和ID
+type taskCursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d"`
+	Value  string `json:"v"`
+	ID     uint   `json:"i"`
+}
+
+// Validate 校验排序字段和分页参数，并解析游标
+func (q *TaskQuery) Validate() error {
+	if q.SortBy == "" {
+		q.SortBy = TaskSortCreatedAt
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = 10
+	}
+	if _, ok := taskSortColumns[q.SortBy]; !ok {
+		return fmt.Errorf("unsupported sort field %q", q.SortBy)
+	}
+
+	q.cursor = nil
+	if q.Cursor == nil || *q.Cursor == "" {
+		return nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(*q.Cursor)
+	if err != nil {
+		return global.ErrorInvalidCursor
+	}
+	var cursor taskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return global.ErrorInvalidCursor
+	}
+	// 游标只能用于生成它的排序方式
+	if cursor.SortBy != q.SortBy || cursor.Desc != q.SortDesc {
+		return global.ErrorInvalidCursor
+	}
+	if _, err := cursor.sortValue(); err != nil {
+		return global.ErrorInvalidCursor
+	}
+	q.cursor = &cursor
+	return nil
+}
+
+// EncodeTaskCursor 以任务在指定排序方式下的位置生成游标
+func EncodeTaskCursor(sortBy string, desc bool, task *model.Task) string {
+	cursor := taskCursor{SortBy: sortBy, Desc: desc, ID: task.ID}
+	switch sortBy {
+	case TaskSortPriority:
+		cursor.Value = strconv.Itoa(task.Priority)
+	case TaskSortCompletedAt:
+		if task.CompletedAt != nil {
+			cursor.Value = task.CompletedAt.Format(time.RFC3339Nano)
+		}
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sortValue 将游标中的排序值转换为对应列的类型
+func (c *taskCursor) sortValue() (interface{}, error) {
+	switch c.SortBy {
+	case TaskSortPriority:
+		return strconv.Atoi(c.Value)
+	case TaskSortCompletedAt:
+		return time.Parse(time.RFC3339Nano, c.Value)
+	}
+	return c.ID, nil
+}
+
+// apply 将过滤条件应用到查询
+func (q *TaskQuery) apply(db *gorm.DB) *gorm.DB {
+	if len(q.Statuses) > 0 {
+		db = db.Where("status IN ?", q.Statuses)
+	}
+	if q.TaskType != "" {
+		db = db.Where("task_type = ?", q.TaskType)
+	}
+	if q.AccountID > 0 {
+		db = db.Where("account_id = ?", q.AccountID)
+	}
+	// 使用子查询过滤，避免连接后同一任务出现多行
+	if q.AccountGroupID > 0 {
+		db = db.Where("account_id IN (?)", global.DB.Model(&model.Account{}).
+			Select("id").Where("account_group_id = ?", q.AccountGroupID))
+	}
+	if q.WorkerID != "" {
+		db = db.Where("task_id IN (?)", global.DB.Model(&model.TaskAssignment{}).
+			Select("task_id").Where("worker_id = ?", q.WorkerID))
+	}
+	if q.MinPriority != nil {
+		db = db.Where("priority >= ?", *q.MinPriority)
+	}
+	if q.MaxPriority != nil {
+		db = db.Where("priority <= ?", *q.MaxPriority)
+	}
+	if q.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *q.CreatedFrom)
+	}
+	if q.CreatedTo != nil {
+		db = db.Where("created_at < ?", *q.CreatedTo)
+	}
+	if q.CompletedFrom != nil {
+		db = db.Where("completed_at >= ?", *q.CompletedFrom)
+	}
+	if q.CompletedTo != nil {
+		db = db.Where("completed_at < ?", *q.CompletedTo)
+	}
+	if q.SortBy == TaskSortCompletedAt {
+		db = db.Where("completed_at IS NOT NULL")
+	}
+	return db
+}
+
+// applyCursor 追加游标位置条件，排序值相同时以ID区分先后
+func (q *TaskQuery) applyCursor(db *gorm.DB) *gorm.DB {
+	if q.cursor == nil {
+		return db
+	}
+
+	op := ">"
+	if q.SortDesc {
+		op = "<"
+	}
+	column := taskSortColumns[q.SortBy]
+	if column == "id" {
+		return db.Where("id "+op+" ?", q.cursor.ID)
+	}
+
+	value, _ := q.cursor.sortValue()
+	return db.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, op, column, op),
+		value, value, q.cursor.ID)
+}
+
+// order 返回排序子句
+func (q *TaskQuery) order() string {
+	dir := "ASC"
+	if q.SortDesc {
+		dir = "DESC"
+	}
+	column := taskSortColumns[q.SortBy]
+	if column == "id" {
+		return "id " + dir
+	}
+	return fmt.Sprintf("%s %s, id %s", column, dir, dir)
+}
+
+// GetTasks 按条件查询任务列表
+// 页码分页返回总数；游标分页不统计总数，按上一页最后一条记录继续查询，避免大偏移量扫描
+func (s *taskServiceImpl) GetTasks(ctx context.Context, query *TaskQuery) (*TaskQueryResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &TaskQueryResult{}
+	db := query.apply(global.DB.Model(&model.Task{}))
+
+	if query.Cursor == nil {
+		// 获取总记录数
+		if err := query.apply(global.DB.Model(&model.Task{})).Count(&result.Total).Error; err != nil {
+			return nil, err
+		}
+		db = db.Offset((query.Page - 1) * query.PageSize)
+	} else {
+		db = query.applyCursor(db)
+	}
+
+	// 多取一条用于判断是否还有下一页
+	var tasks []*model.Task
+	if err := db.Preload("Account").
+		Order(query.order()).
+		Limit(query.PageSize + 1).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	if len(tasks) > query.PageSize {
+		tasks = tasks[:query.PageSize]
+		if query.Cursor != nil {
+			result.NextCursor = EncodeTaskCursor(query.SortBy, query.SortDesc, tasks[len(tasks)-1])
+		}
+	}
+	result.List = tasks
+
+	return result, nil
+}
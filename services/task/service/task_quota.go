@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"tg_manager_api/config"
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// 配额计数在Redis中的前缀
+const taskQuotaKeyPrefix = "task:quota:"
+
+// 默认配额窗口
+const defaultTaskQuotaWindow = 24 * time.Hour
+
+// TaskQuotaUsage 账号某类任务的配额使用情况
+type TaskQuotaUsage struct {
+	TaskType   string     `json:"task_type"`              // 任务类型
+	Limit      int        `json:"limit"`                  // 窗口内的配额
+	Used       int        `json:"used"`                   // 窗口内已占用的配额，包括推迟执行的任务
+	Remaining  int        `json:"remaining"`              // 剩余配额
+	NextSlotAt *time.Time `json:"next_slot_at,omitempty"` // 配额用完时新任务最早的执行时间
+}
+
+// 占用配额并返回任务的执行时间，窗口内配额已用完时推迟到最早空出配额的时间
+// 配额记录为有序集合，成员为任务ID，分值为任务的执行时间(毫秒)
+var reserveTaskQuotaScript = redis.NewScript(`
+local now, desired, window, limit = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3]), tonumber(ARGV[4])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local n = redis.call('ZCARD', KEYS[1])
+local at = desired
+if n >= limit then
+	local slot = redis.call('ZRANGE', KEYS[1], n - limit, n - limit, 'WITHSCORES')
+	local free = tonumber(slot[2]) + window
+	if free > at then
+		at = free
+	end
+end
+redis.call('ZADD', KEYS[1], at, ARGV[5])
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+redis.call('PEXPIREAT', KEYS[1], tonumber(last[2]) + window)
+return at
+`)
+
+// 配额窗口时长
+func taskQuotaWindow() time.Duration {
+	if global.Config.Task.Quota.Window > 0 {
+		return time.Duration(global.Config.Task.Quota.Window) * time.Second
+	}
+	return defaultTaskQuotaWindow
+}
+
+// 账号某类任务的配额记录key
+func taskQuotaKey(accountID uint, taskType string) string {
+	return fmt.Sprintf("%s%d:%s", taskQuotaKeyPrefix, accountID, strings.ToLower(taskType))
+}
+
+// TaskQuotaLimit 账号在窗口内可执行的某类任务数，0表示不限制
+// 账号分组的配置优先于账号等级，二者都未配置时使用任务类型的默认配额
+func TaskQuotaLimit(cfg config.TaskQuota, taskType string, accountGroupID uint, accountLevel int) int {
+	// 配置文件加载后key统一为小写
+	taskType = strings.ToLower(taskType)
+	lookup := func(limits map[string]int) (int, bool) {
+		for key, limit := range limits {
+			if strings.ToLower(key) == taskType {
+				return limit, true
+			}
+		}
+		return 0, false
+	}
+
+	if limit, ok := lookup(cfg.Groups[strconv.FormatUint(uint64(accountGroupID), 10)]); ok {
+		return limit
+	}
+	if limit, ok := lookup(cfg.Levels[strconv.Itoa(accountLevel)]); ok {
+		return limit
+	}
+	limit, _ := lookup(cfg.Limits)
+	return limit
+}
+
+// 为新任务占用账号的配额，超出配额时将任务推迟到窗口内最早空出配额的时间
+// 未配置Redis或任务类型不限制时直接返回，返回值表示任务是否被推迟
+func reserveTaskQuota(ctx context.Context, task *model.Task, account *model.Account) (bool, error) {
+	limit := TaskQuotaLimit(global.Config.Task.Quota, task.TaskType, account.AccountGroupID, account.AccountLevel)
+	if limit <= 0 || global.Redis == nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	desired := now
+	if task.RunAt != nil && task.RunAt.After(now) {
+		desired = *task.RunAt
+	}
+
+	at, err := reserveTaskQuotaScript.Run(ctx, global.Redis, []string{taskQuotaKey(task.AccountID, task.TaskType)},
+		now.UnixMilli(), desired.UnixMilli(), taskQuotaWindow().Milliseconds(), limit, task.TaskID).Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve task quota: %w", err)
+	}
+	if at <= desired.UnixMilli() {
+		return false, nil
+	}
+
+	runAt := time.UnixMilli(at)
+	task.RunAt = &runAt
+	return true, nil
+}
+
+// 释放尚未执行的任务占用的配额
+func releaseTaskQuota(ctx context.Context, task *model.Task) {
+	if global.Redis == nil {
+		return
+	}
+	global.Redis.ZRem(ctx, taskQuotaKey(task.AccountID, task.TaskType), task.TaskID)
+}
+
+// 批量为新任务占用配额，返回被推迟的任务，任一任务失败时释放已占用的配额
+func reserveTaskQuotas(ctx context.Context, tasks []*model.Task, accounts map[uint]*model.Account) ([]*model.Task, error) {
+	var deferred []*model.Task
+	for i, task := range tasks {
+		ok, err := reserveTaskQuota(ctx, task, accounts[task.AccountID])
+		if err != nil {
+			releaseTaskQuotas(ctx, tasks[:i])
+			return nil, err
+		}
+		if ok {
+			deferred = append(deferred, task)
+		}
+	}
+	return deferred, nil
+}
+
+// 释放一组任务占用的配额
+func releaseTaskQuotas(ctx context.Context, tasks []*model.Task) {
+	for _, task := range tasks {
+		releaseTaskQuota(ctx, task)
+	}
+}
+
+// 记录任务因配额被推迟
+func logQuotaDeferred(task *model.Task) {
+	AppendTaskLog(task.TaskID, "", TaskLogInfo, "Daily quota for %s exhausted, deferred to %s",
+		task.TaskType, task.RunAt.Format(time.RFC3339))
+}
+
+// GetAccountQuotas 获取账号各类任务的配额使用情况，仅包含有配额限制的任务类型
+func GetAccountQuotas(ctx context.Context, accountID uint) ([]*TaskQuotaUsage, error) {
+	var account model.Account
+	if err := global.DB.First(&account, accountID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, global.ErrorAccountNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	window := taskQuotaWindow()
+	usages := make([]*TaskQuotaUsage, 0)
+	for _, def := range ListTaskTypes() {
+		limit := TaskQuotaLimit(global.Config.Task.Quota, def.Type, account.AccountGroupID, account.AccountLevel)
+		if limit <= 0 {
+			continue
+		}
+
+		usage := &TaskQuotaUsage{TaskType: def.Type, Limit: limit, Remaining: limit}
+		usages = append(usages, usage)
+		if global.Redis == nil {
+			continue
+		}
+
+		// 与占用配额时的计算一致，窗口开始之后的记录都计入已占用
+		key := taskQuotaKey(account.ID, def.Type)
+		min := strconv.FormatInt(now.Add(-window).UnixMilli(), 10)
+		slots, err := global.Redis.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: "(" + min, Max: "+inf"}).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		usage.Used = len(slots)
+		if usage.Used < limit {
+			usage.Remaining = limit - usage.Used
+			continue
+		}
+		usage.Remaining = 0
+		next := time.UnixMilli(int64(slots[usage.Used-limit].Score)).Add(window)
+		usage.NextSlotAt = &next
+	}
+	return usages, nil
+}
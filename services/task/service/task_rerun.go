@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// 单次批量重新执行的任务数上限
+const maxRerunTasks = 500
+
+// 批量重新执行时可选择的失败状态
+var rerunStatuses = []string{string(TaskStatusFailed), string(TaskStatusTimeout)}
+
+// TaskRerunItem 单个任务的重新执行结果
+type TaskRerunItem struct {
+	OriginTaskID string `json:"origin_task_id"`    // 原任务ID
+	TaskID       string `json:"task_id,omitempty"` // 新任务ID
+	Error        string `json:"error,omitempty"`   // 创建失败的原因
+}
+
+// TaskRerunResult 批量重新执行的结果
+type TaskRerunResult struct {
+	Matched int             `json:"matched"` // 符合条件的任务数，最多500个
+	Created int             `json:"created"` // 成功创建的任务数
+	Items   []TaskRerunItem `json:"items"`   // 每个任务的结果
+}
+
+// RerunTask 以已结束任务的类型、账号、参数、优先级、超时和重试设置创建新任务
+// overrides中的参数覆盖原参数，值为null时删除该参数，新任务通过rerun_of关联原任务
+func (s *taskServiceImpl) RerunTask(ctx context.Context, taskID string, overrides map[string]interface{}) (*model.Task, error) {
+	origin, err := findTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !IsFinishedStatus(origin.Status) {
+		return nil, global.ErrorInvalidTaskStatus
+	}
+
+	return s.rerun(ctx, origin, overrides, false)
+}
+
+// RerunTasks 重新执行符合条件的失败或超时任务，已重新执行过的任务不会重复创建
+// 条件中的状态只能为failed或timeout，未指定时包括两者
+func (s *taskServiceImpl) RerunTasks(ctx context.Context, query *TaskQuery, overrides map[string]interface{}) (*TaskRerunResult, error) {
+	statuses := rerunStatuses
+	if len(query.Statuses) > 0 {
+		statuses = nil
+		for _, status := range query.Statuses {
+			if status == string(TaskStatusFailed) || status == string(TaskStatusTimeout) {
+				statuses = append(statuses, status)
+			}
+		}
+		if len(statuses) == 0 {
+			return nil, global.ErrorInvalidTaskStatus
+		}
+	}
+
+	filter := *query
+	filter.Statuses = statuses
+	filter.SortBy = ""
+
+	var origins []*model.Task
+	if err := filter.apply(global.DB.Model(&model.Task{})).
+		Where("task_id NOT IN (?)", global.DB.Model(&model.Task{}).Select("rerun_of").Where("rerun_of <> ''")).
+		Order("id ASC").
+		Limit(maxRerunTasks).
+		Find(&origins).Error; err != nil {
+		return nil, err
+	}
+
+	result := &TaskRerunResult{Matched: len(origins), Items: make([]TaskRerunItem, 0, len(origins))}
+	for _, origin := range origins {
+		item := TaskRerunItem{OriginTaskID: origin.TaskID}
+		task, err := s.rerunOnce(ctx, origin, overrides)
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			item.TaskID = task.TaskID
+			result.Created++
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+// MergeRerunParams 以overrides覆盖原任务参数，值为null时删除该参数，不修改原参数
+func MergeRerunParams(params, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(params)+len(overrides))
+	for key, value := range params {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// 原任务尚未被批量重新执行时创建新任务，已重新执行过时返回ErrorTaskAlreadyRerun
+// 由bulk_rerun_of的唯一索引保证并发的批量重新执行不会为同一任务重复创建
+func (s *taskServiceImpl) rerunOnce(ctx context.Context, origin *model.Task, overrides map[string]interface{}) (*model.Task, error) {
+	task, err := s.rerun(ctx, origin, overrides, true)
+	if err == nil {
+		return task, nil
+	}
+
+	// 写入失败时检查是否已被并发的批量重新执行创建
+	var reruns int64
+	if countErr := global.DB.Model(&model.Task{}).
+		Where("bulk_rerun_of = ?", origin.TaskID).
+		Count(&reruns).Error; countErr == nil && reruns > 0 {
+		return nil, global.ErrorTaskAlreadyRerun
+	}
+	return nil, err
+}
+
+// 按原任务创建新任务，参数和配额校验与普通创建一致
+func (s *taskServiceImpl) rerun(ctx context.Context, origin *model.Task, overrides map[string]interface{}, bulk bool) (*model.Task, error) {
+	params := MergeRerunParams(origin.Params, overrides)
+
+	opts := &CreateTaskOptions{
+		Priority:           &origin.Priority,
+		TimeoutSec:         &origin.TimeoutSec,
+		MaxAttempts:        &origin.MaxAttempts,
+		RetryBackoffSec:    &origin.RetryBackoffSec,
+		RetryBackoffMaxSec: &origin.RetryBackoffMaxSec,
+		RetryableErrors:    SplitTags(origin.RetryableErrors),
+		RequiredTags:       SplitTags(origin.RequiredTags),
+		RerunOf:            origin.TaskID,
+		BulkRerun:          bulk,
+	}
+	task, err := s.CreateTask(ctx, origin.TaskType, origin.AccountID, params, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	AppendTaskLog(origin.TaskID, "", TaskLogInfo, "Rerun as task %s", task.TaskID)
+	return task, nil
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// 默认时区
+const defaultScheduleTimezone = "Asia/Shanghai"
+
+// TaskScheduleServiceI 周期任务计划服务接口
+type TaskScheduleServiceI interface {
+	// 创建周期计划
+	CreateSchedule(ctx context.Context, schedule *model.TaskSchedule) (*model.TaskSchedule, error)
+
+	// 获取周期计划列表
+	GetSchedules(ctx context.Context, page, pageSize int) ([]*model.TaskSchedule, int64, error)
+
+	// 获取周期计划详情
+	GetSchedule(ctx context.Context, id uint) (*model.TaskSchedule, error)
+
+	// 更新周期计划
+	UpdateSchedule(ctx context.Context, schedule *model.TaskSchedule) error
+
+	// 删除周期计划
+	DeleteSchedule(ctx context.Context, id uint) error
+
+	// 为到期的周期计划生成任务，返回生成的任务数
+	RunDueSchedules(ctx context.Context, now time.Time) (int, error)
+}
+
+// NewTaskScheduleService 创建周期任务计划服务实例
+func NewTaskScheduleService() TaskScheduleServiceI {
+	return &taskScheduleService{}
+}
+
+// taskScheduleService 周期任务计划服务实现
+type taskScheduleService struct{}
+
+// NextRunTime 计算Cron表达式在after之后的下一次执行时间
+func NextRunTime(cronExpr, timezone string, after time.Time) (time.Time, error) {
+	if timezone == "" {
+		timezone = defaultScheduleTimezone
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	schedule, err := cron.ParseStandard(cronExpr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression %q: %w", cronExpr, err)
+	}
+
+	next := schedule.Next(after.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q never fires", cronExpr)
+	}
+	return next, nil
+}
+
+// CreateSchedule 创建周期计划
+func (s *taskScheduleService) CreateSchedule(ctx context.Context, schedule *model.TaskSchedule) (*model.TaskSchedule, error) {
+	// 校验任务类型和参数
+	if err := ValidateTaskParams(schedule.TaskType, schedule.Params); err != nil {
+		return nil, err
+	}
+
+	// 检查账号是否存在
+	var account model.Account
+	if err := global.DB.First(&account, schedule.AccountID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, global.ErrorAccountNotFound
+		}
+		return nil, err
+	}
+
+	if schedule.Timezone == "" {
+		schedule.Timezone = defaultScheduleTimezone
+	}
+	if schedule.TimeoutSec <= 0 {
+		schedule.TimeoutSec, _ = taskTypeDefaults(schedule.TaskType)
+	}
+
+	// 计算首次执行时间
+	next, err := NextRunTime(schedule.CronExpr, schedule.Timezone, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = &next
+
+	// Enabled为false时gorm会使用默认值，需显式写入
+	if err := global.DB.Create(schedule).Error; err != nil {
+		return nil, err
+	}
+	if !schedule.Enabled {
+		if err := global.DB.Model(schedule).Update("enabled", false).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return schedule, nil
+}
+
+// GetSchedules 获取周期计划列表
+func (s *taskScheduleService) GetSchedules(ctx context.Context, page, pageSize int) ([]*model.TaskSchedule, int64, error) {
+	var schedules []*model.TaskSchedule
+	var total int64
+
+	// 获取总记录数
+	if err := global.DB.Model(&model.TaskSchedule{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	if err := global.DB.Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&schedules).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return schedules, total, nil
+}
+
+// GetSchedule 获取周期计划详情
+func (s *taskScheduleService) GetSchedule(ctx context.Context, id uint) (*model.TaskSchedule, error) {
+	var schedule model.TaskSchedule
+	if err := global.DB.Preload("Account").First(&schedule, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, global.ErrorScheduleNotFound
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// UpdateSchedule 更新周期计划
+func (s *taskScheduleService) UpdateSchedule(ctx context.Context, schedule *model.TaskSchedule) error {
+	// 参数可能已变化，重新校验
+	if err := ValidateTaskParams(schedule.TaskType, schedule.Params); err != nil {
+		return err
+	}
+
+	if schedule.Timezone == "" {
+		schedule.Timezone = defaultScheduleTimezone
+	}
+
+	var current model.TaskSchedule
+	if err := global.DB.Select("id", "cron_expr", "timezone").First(&current, schedule.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return global.ErrorScheduleNotFound
+		}
+		return err
+	}
+
+	// 只写入可修改的字段，不回写关联的账号
+	columns := []string{"name", "params", "priority", "timeout_sec", "required_tags", "cron_expr", "timezone", "enabled"}
+
+	// Cron表达式或时区变化时重新计算下次执行时间
+	if schedule.CronExpr != current.CronExpr || schedule.Timezone != current.Timezone {
+		next, err := NextRunTime(schedule.CronExpr, schedule.Timezone, time.Now())
+		if err != nil {
+			return err
+		}
+		schedule.NextRunAt = &next
+		columns = append(columns, "next_run_at")
+	}
+
+	return global.DB.Model(schedule).Omit(clause.Associations).Select(columns).Updates(schedule).Error
+}
+
+// DeleteSchedule 删除周期计划
+func (s *taskScheduleService) DeleteSchedule(ctx context.Context, id uint) error {
+	result := global.DB.Delete(&model.TaskSchedule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return global.ErrorScheduleNotFound
+	}
+	return nil
+}
+
+// RunDueSchedules 为到期的周期计划生成任务
+func (s *taskScheduleService) RunDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	var schedules []model.TaskSchedule
+	if err := global.DB.Where("enabled = ? AND next_run_at <= ?", true, now).
+		Find(&schedules).Error; err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for i := range schedules {
+		ok, err := s.runSchedule(&schedules[i], now)
+		if err != nil {
+			global.Logger.Error("周期计划生成任务失败", zap.Uint("schedule_id", schedules[i].ID), zap.Error(err))
+			continue
+		}
+		if ok {
+			created++
+		}
+	}
+
+	return created, nil
+}
+
+// runSchedule 为单个周期计划生成任务并推进下次执行时间
+// 错过的多次执行只补一次，下次执行时间从当前时间开始计算
+func (s *taskScheduleService) runSchedule(schedule *model.TaskSchedule, now time.Time) (bool, error) {
+	next, err := NextRunTime(schedule.CronExpr, schedule.Timezone, now)
+	if err != nil {
+		return false, err
+	}
+
+	task := newTask(schedule.TaskType, schedule.AccountID, schedule.Params)
+	task.Priority = schedule.Priority
+	task.TimeoutSec = schedule.TimeoutSec
+	task.RequiredTags = schedule.RequiredTags
+	task.ScheduleID = schedule.ID
+
+	// 暂停调度的账号的任务创建为暂停状态
+	if err := pauseNewTasks([]*model.Task{task}); err != nil {
+		return false, err
+	}
+
+	created, reserved, deferred := false, false, false
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		// 以原下次执行时间作为条件推进，避免同一周期重复生成任务
+		result := tx.Model(&model.TaskSchedule{}).
+			Where("id = ? AND next_run_at = ?", schedule.ID, schedule.NextRunAt).
+			Updates(map[string]interface{}{
+				"next_run_at":  next,
+				"last_run_at":  now,
+				"last_task_id": task.TaskID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		// 占用账号的任务配额，超出配额时推迟到有配额空出时执行
+		var account model.Account
+		if err := tx.Select("id", "account_group_id", "account_level").First(&account, schedule.AccountID).Error; err != nil {
+			return err
+		}
+		var err error
+		if deferred, err = reserveTaskQuota(context.Background(), task, &account); err != nil {
+			return err
+		}
+		reserved = true
+
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		history := newCreationHistory(task, TaskActorScheduler, fmt.Sprintf("Created by schedule %d", schedule.ID))
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil && reserved {
+		releaseTaskQuota(context.Background(), task)
+	}
+	if created && deferred {
+		logQuotaDeferred(task)
+	}
+
+	return created, err
+}
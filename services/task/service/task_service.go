@@ -5,17 +5,6 @@ import (
 	"time"
 )
 
-// TaskStatus 任务状态
-type TaskStatus string
-
-const (
-	TaskStatusPending   TaskStatus = "PENDING"   // 等待执行
-	TaskStatusRunning   TaskStatus = "RUNNING"   // 执行中
-	TaskStatusCompleted TaskStatus = "COMPLETED" // 已完成
-	TaskStatusFailed    TaskStatus = "FAILED"    // 失败
-	TaskStatusCanceled  TaskStatus = "CANCELED"  // 已取消
-)
-
 // TaskType 任务类型
 type TaskType string
 
@@ -4,14 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 	
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 	
 	"tg_manager_api/global"
 	"tg_manager_api/model"
 	"tg_manager_api/services/rabbitmq"
+	"tg_manager_api/services/task/dispatch"
 	"tg_manager_api/services/worker/service"
 	"tg_manager_api/utils"
 )
@@ -19,10 +22,10 @@ import (
 // TaskServiceI 任务服务接口
 type TaskServiceI interface {
 	// 创建任务
-	CreateTask(ctx context.Context, taskType string, accountID uint, params map[string]interface{}) (string, error)
+	CreateTask(ctx context.Context, taskType string, accountID uint, params map[string]interface{}, opts *CreateTaskOptions) (string, error)
 	
-	// 获取任务列表
-	GetTasks(ctx context.Context, page, pageSize int) ([]*Task, int64, error)
+	// 按条件查询任务列表，支持页码分页和游标分页
+	GetTasks(ctx context.Context, query *TaskQuery) (*TaskQueryResult, error)
 	
 	// 获取任务详情
 	GetTask(ctx context.Context, taskID string) (*Task, error)
@@ -30,11 +33,32 @@ type TaskServiceI interface {
 	// 更新任务状态
 	UpdateTaskStatus(ctx context.Context, taskID string, status TaskStatus, result string, errorMsg string) error
 	
+	// 获取任务状态变化时间线
+	GetTaskTimeline(ctx context.Context, taskID string) ([]*model.TaskStatusHistory, error)
+	
 	// 取消任务
 	CancelTask(ctx context.Context, taskID string) error
 	
+	// 暂停等待调度的任务
+	PauseTask(ctx context.Context, taskID string) error
+	
+	// 恢复已暂停的任务
+	ResumeTask(ctx context.Context, taskID string) error
+	
+	// 暂停账号或账号分组下所有等待调度的任务，返回暂停的任务数
+	PauseTasks(ctx context.Context, scope TaskPauseScope) (int, error)
+	
+	// 恢复账号或账号分组下所有已暂停的任务，返回恢复的任务数
+	ResumeTasks(ctx context.Context, scope TaskPauseScope) (int, error)
+	
+	// 以已结束任务的类型、账号、参数、优先级和超时时间创建新任务
+	RerunTask(ctx context.Context, taskID string, overrides map[string]interface{}) (*model.Task, error)
+	
+	// 重新执行所有符合条件且尚未重新执行过的失败任务
+	RerunTasks(ctx context.Context, query *TaskQuery, overrides map[string]interface{}) (*TaskRerunResult, error)
+	
 	// 获取任务日志
-	GetTaskLogs(ctx context.Context, taskID string, page, pageSize int) ([]string, int64, error)
+	GetTaskLogs(ctx context.Context, taskID, level string, page, pageSize int) ([]*model.TaskLog, int64, error)
 }
 
 // taskService 任务服务实现
@@ -45,8 +69,64 @@ type taskServiceImpl struct {
 	workerService service.WorkerServiceI
 }
 
+// CreateTaskOptions 创建任务的可选参数，为空的字段使用默认值
+type CreateTaskOptions struct {
+	Priority           *int       // 优先级
+	TimeoutSec         *int       // 超时时间(秒)
+	RunAt              *time.Time // 计划执行时间
+	MaxAttempts        *int       // 最大尝试次数
+	RetryBackoffSec    *int       // 重试退避基数(秒)
+	RetryBackoffMaxSec *int       // 重试退避上限(秒)
+	RetryableErrors    []string   // 可重试的错误类型
+	RequiredTags       []string   // 所需的工作节点标签
+	IdempotencyKey     string     // 幂等键，保留期内相同的键只会创建一个任务
+	RerunOf            string     // 重新执行的原任务ID
+	BulkRerun          bool       // 是否为批量重新执行，同一原任务只能批量重新执行一次
+}
+
+// apply 将可选参数写入任务
+func (o *CreateTaskOptions) apply(task *model.Task) {
+	if o == nil {
+		return
+	}
+	if o.Priority != nil {
+		task.Priority = *o.Priority
+	}
+	if o.TimeoutSec != nil && *o.TimeoutSec > 0 {
+		task.TimeoutSec = *o.TimeoutSec
+	}
+	if o.RunAt != nil && o.RunAt.After(time.Now()) {
+		task.RunAt = o.RunAt
+	}
+	if o.MaxAttempts != nil && *o.MaxAttempts > 0 {
+		task.MaxAttempts = *o.MaxAttempts
+	}
+	if o.RetryBackoffSec != nil {
+		task.RetryBackoffSec = *o.RetryBackoffSec
+	}
+	if o.RetryBackoffMaxSec != nil {
+		task.RetryBackoffMaxSec = *o.RetryBackoffMaxSec
+	}
+	if len(o.RetryableErrors) > 0 {
+		task.RetryableErrors = strings.Join(o.RetryableErrors, ",")
+	}
+	if len(o.RequiredTags) > 0 {
+		task.RequiredTags = JoinTags(o.RequiredTags)
+	}
+	task.RerunOf = o.RerunOf
+	if o.BulkRerun && o.RerunOf != "" {
+		rerunOf := o.RerunOf
+		task.BulkRerunOf = &rerunOf
+	}
+}
+
 // CreateTask 创建任务
-func (s *taskServiceImpl) CreateTask(ctx context.Context, taskType string, accountID uint, params map[string]interface{}) (*model.Task, error) {
+func (s *taskServiceImpl) CreateTask(ctx context.Context, taskType string, accountID uint, params map[string]interface{}, opts *CreateTaskOptions) (*model.Task, error) {
+	// 校验任务类型和参数
+	if err := ValidateTaskParams(taskType, params); err != nil {
+		return nil, err
+	}
+	
 	// 检查账号是否存在
 	var account model.Account
 	if err := global.DB.First(&account, accountID).Error; err != nil {
@@ -56,52 +136,79 @@ func (s *taskServiceImpl) CreateTask(ctx context.Context, taskType string, accou
 		return nil, err
 	}
 	
-	// 生成任务ID
-	taskID := generateTaskID()
+	// 带幂等键的请求先占用幂等键，重复请求直接返回原任务
+	// 未配置Redis时无法保证幂等，拒绝请求而不是忽略幂等键
+	idempotencyKey, payloadHash := "", ""
+	if opts != nil && opts.IdempotencyKey != "" {
+		if global.Redis == nil {
+			return nil, global.ErrorIdempotencyUnavailable
+		}
+		hash, err := idempotencyPayloadHash(taskType, accountID, params, opts)
+		if err != nil {
+			return nil, err
+		}
+		existingID, err := claimIdempotencyKey(ctx, opts.IdempotencyKey, hash)
+		if err != nil {
+			return nil, err
+		}
+		if existingID != "" {
+			return s.GetTask(ctx, existingID)
+		}
+		idempotencyKey, payloadHash = opts.IdempotencyKey, hash
+	}
 	
 	// 创建任务记录
-	task := &model.Task{
-		TaskID:    taskID,
-		TaskType:  taskType,
-		AccountID: accountID,
-		Params:    params,
-		Status:    "pending",
-		Priority:  0, // 默认优先级
-		TimeoutSec: 300, // 默认5分钟超时
-	}
+	task := newTask(taskType, accountID, params)
+	opts.apply(task)
 	
-	// 保存到数据库
-	if err := global.DB.Create(task).Error; err != nil {
+	// 暂停调度的账号的任务创建为暂停状态
+	if err := pauseNewTasks([]*model.Task{task}); err != nil {
+		if idempotencyKey != "" {
+			releaseIdempotencyKey(ctx, idempotencyKey)
+		}
 		return nil, err
 	}
 	
-	// 尝试分配任务给可用的工作节点
-	go s.AssignTask(context.Background(), task)
+	// 占用账号的任务配额，超出配额时推迟到有配额空出时执行
+	deferred, err := reserveTaskQuota(ctx, task, &account)
+	if err != nil {
+		if idempotencyKey != "" {
+			releaseIdempotencyKey(ctx, idempotencyKey)
+		}
+		return nil, err
+	}
 	
-	return task, nil
-}
-
-// GetTasks 获取任务列表
-func (s *taskServiceImpl) GetTasks(ctx context.Context, page, pageSize int) ([]*model.Task, int64, error) {
-	var tasks []*model.Task
-	var total int64
+	// 保存到数据库，同时记录初始状态
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		history := newCreationHistory(task, TaskActorUser, "Created")
+		return tx.Create(&history).Error
+	})
+	if err != nil {
+		releaseTaskQuota(ctx, task)
+		if idempotencyKey != "" {
+			releaseIdempotencyKey(ctx, idempotencyKey)
+		}
+		return nil, err
+	}
+	if deferred {
+		logQuotaDeferred(task)
+	}
 	
-	// 获取总记录数
-	if err := global.DB.Model(&model.Task{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	if idempotencyKey != "" {
+		if err := completeIdempotencyKey(ctx, idempotencyKey, payloadHash, task.TaskID); err != nil {
+			global.Logger.Warn("记录幂等键失败", zap.String("task_id", task.TaskID), zap.Error(err))
+		}
 	}
 	
-	// 分页查询
-	offset := (page - 1) * pageSize
-	if err := global.DB.Preload("Account").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&tasks).Error; err != nil {
-		return nil, 0, err
+	// 通知调度器立即分配，定时任务由调度器到期后分配
+	if task.RunAt == nil && task.Status == string(TaskStatusPending) {
+		dispatch.Notify("task_created")
 	}
 	
-	return tasks, total, nil
+	return task, nil
 }
 
 // GetTask 获取任务详情
@@ -121,8 +228,9 @@ func (s *taskServiceImpl) GetTask(ctx context.Context, taskID string) (*model.Ta
 	return &task, nil
 }
 
-// UpdateTaskStatus 更新任务状态
-func (s *taskServiceImpl) UpdateTaskStatus(ctx context.Context, taskID, status string, result map[string]interface{}, errorMsg string) error {
+// UpdateTaskStatus 更新任务状态，仅允许状态机中定义的转换
+// 操作者通过WithTaskActor写入ctx，未设置时记为system
+func (s *taskServiceImpl) UpdateTaskStatus(ctx context.Context, taskID string, status TaskStatus, result map[string]interface{}, errorMsg string) error {
 	// 查找任务
 	var task model.Task
 	if err := global.DB.Where("task_id = ?", taskID).First(&task).Error; err != nil {
@@ -132,32 +240,55 @@ func (s *taskServiceImpl) UpdateTaskStatus(ctx context.Context, taskID, status s
 		return err
 	}
 	
-	// 更新状态
-	updateFields := map[string]interface{}{
-		"status": status,
-	}
-	
 	// 根据状态设置其他字段
+	updateFields := map[string]interface{}{}
 	switch status {
-	case "processing":
+	case TaskStatusAssigned:
+		updateFields["attempt"] = gorm.Expr("attempt + 1")
+		updateFields["next_retry_at"] = nil
+	case TaskStatusProcessing:
 		now := time.Now()
 		updateFields["started_at"] = now
-	case "completed", "failed":
+	case TaskStatusCompleted, TaskStatusFailed, TaskStatusCanceled, TaskStatusTimeout:
 		now := time.Now()
 		updateFields["completed_at"] = now
 		if errorMsg != "" {
 			updateFields["error_message"] = errorMsg
+			task.ErrorMessage = errorMsg
 		}
 	}
 	
-	// 更新数据库
-	if err := global.DB.Model(&task).Updates(updateFields).Error; err != nil {
+	// 按状态机更新状态并记录历史
+	if err := TransitionTaskStatus(global.DB, &task, status, taskActor(ctx), errorMsg, updateFields); err != nil {
 		return err
 	}
 	
+	// 通知订阅者状态变化
+	PublishTaskEvent(NewTaskEvent(&task, string(status)))
+	
 	return nil
 }
 
+// GetTaskTimeline 获取任务状态变化时间线，按时间先后排列
+func (s *taskServiceImpl) GetTaskTimeline(ctx context.Context, taskID string) ([]*model.TaskStatusHistory, error) {
+	var count int64
+	if err := global.DB.Model(&model.Task{}).Where("task_id = ?", taskID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, global.ErrorTaskNotFound
+	}
+	
+	var history []*model.TaskStatusHistory
+	if err := global.DB.Where("task_id = ?", taskID).
+		Order("changed_at ASC, id ASC").
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+	
+	return history, nil
+}
+
 // CancelTask 取消任务
 func (s *taskServiceImpl) CancelTask(ctx context.Context, taskID string) error {
 	// 查找任务
@@ -169,25 +300,68 @@ func (s *taskServiceImpl) CancelTask(ctx context.Context, taskID string) error {
 		return err
 	}
 	
-	// 只有待处理或处理中的任务可以取消
-	if task.Status != "pending" && task.Status != "processing" {
+	switch TaskStatus(task.Status) {
+	case TaskStatusBlocked, TaskStatusPending, TaskStatusPaused:
+		// 尚未分配的任务直接取消
+		err := TransitionTaskStatus(global.DB, &task, TaskStatusCanceled, TaskActorUser, "Task canceled by user", map[string]interface{}{
+			"completed_at":  time.Now(),
+			"error_message": "Task canceled by user",
+		})
+		if err == global.ErrorTaskStatusConflict {
+			// 状态已被调度器修改，按最新状态重新处理
+			return s.CancelTask(ctx, taskID)
+		}
+		if err != nil {
+			return err
+		}
+		task.ErrorMessage = "Task canceled by user"
+		PublishTaskEvent(NewTaskEvent(&task, "canceled"))
+		AppendTaskLog(taskID, "", TaskLogInfo, "Canceled by user")
+		
+		// 未执行的任务归还占用的配额
+		releaseTaskQuota(ctx, &task)
+		
+		// 按工作流失败策略处理下游任务
+		return resolveDependents(&task, false)
+	case TaskStatusAssigned, TaskStatusProcessing, TaskStatusCanceling:
+		// 已分配的任务需等待工作节点确认后才算取消
+		return s.requestWorkerCancel(&task)
+	default:
 		return global.ErrorInvalidTaskStatus
 	}
-	
-	// 更新任务状态为取消
-	if err := global.DB.Model(&task).Updates(map[string]interface{}{
-		"status":        "canceled",
-		"completed_at":  time.Now(),
-		"error_message": "Task canceled by user",
-	}).Error; err != nil {
+}
+
+// 通知执行中任务的工作节点取消任务，任务进入canceling状态
+// 工作节点通过结果交换机回复canceled状态后，调度器完成取消并释放节点槽位
+func (s *taskServiceImpl) requestWorkerCancel(task *model.Task) error {
+	// 查找当前的分配记录
+	var assignment model.TaskAssignment
+	if err := global.DB.Where("task_id = ? AND completed_at IS NULL", task.TaskID).
+		Order("id DESC").First(&assignment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return global.ErrorInvalidTaskStatus
+		}
 		return err
 	}
 	
-	// 如果已分配给工作节点，还需要通知工作节点取消任务
-	// 这里简化处理，仅更新数据库状态
-	// 实际实现中应该通过消息队列通知工作节点
+	if task.Status != string(TaskStatusCanceling) {
+		err := TransitionTaskStatus(global.DB, task, TaskStatusCanceling, TaskActorUser, "Task canceled by user", map[string]interface{}{
+			"error_message": "Task canceled by user",
+		})
+		if err == global.ErrorTaskStatusConflict {
+			// 任务已结束
+			return global.ErrorInvalidTaskStatus
+		}
+		if err != nil {
+			return err
+		}
+		task.ErrorMessage = "Task canceled by user"
+		PublishTaskEvent(NewTaskEvent(task, "canceling"))
+		AppendTaskLog(task.TaskID, assignment.WorkerID, TaskLogInfo, "Cancel requested, waiting for worker %s to confirm", assignment.WorkerID)
+	}
 	
-	return nil
+	// 重复取消时再次发送通知，以防之前的消息丢失
+	return s.sendCancelToQueue(task.TaskID, assignment.WorkerID)
 }
 
 // ProcessTaskResult 处理任务结果
@@ -205,9 +379,9 @@ func (s *taskServiceImpl) ProcessTaskResult(ctx context.Context, taskID string,
 	var assignment model.TaskAssignment
 	if err := global.DB.Where("task_id = ?", taskID).First(&assignment).Error; err != nil {
 		// 如果没有分配记录，仅更新任务状态
-		status := "completed"
+		status := TaskStatusCompleted
 		if !success {
-			status = "failed"
+			status = TaskStatusFailed
 		}
 		
 		return s.UpdateTaskStatus(ctx, taskID, status, result, errorMsg)
@@ -227,6 +401,7 @@ func (s *taskServiceImpl) ProcessTaskResult(ctx context.Context, taskID string,
 	
 	// 计算执行时间（毫秒）
 	taskRecord.ExecutionTime = int(now.Sub(assignment.AssignedAt).Milliseconds())
+	ApplyTaskTimings(&taskRecord, &task)
 	
 	// 保存任务记录
 	if err := global.DB.Create(&taskRecord).Error; err != nil {
@@ -234,14 +409,14 @@ func (s *taskServiceImpl) ProcessTaskResult(ctx context.Context, taskID string,
 	}
 	
 	// 更新任务状态
-	status := "completed"
+	status := TaskStatusCompleted
 	if !success {
-		status = "failed"
+		status = TaskStatusFailed
 	}
 	
 	// 更新任务分配状态
 	if err := global.DB.Model(&assignment).Updates(map[string]interface{}{
-		"status":       status,
+		"status":       string(status),
 		"completed_at": now,
 	}).Error; err != nil {
 		return err
@@ -256,64 +431,45 @@ func (s *taskServiceImpl) ProcessTaskResult(ctx context.Context, taskID string,
 	}
 	
 	// 更新任务状态
-	return s.UpdateTaskStatus(ctx, taskID, status, result, errorMsg)
+	return s.UpdateTaskStatus(WithTaskActor(ctx, assignment.WorkerID), taskID, status, result, errorMsg)
 }
 
-// AssignTask 分配任务
-func (s *taskServiceImpl) AssignTask(ctx context.Context, task *model.Task) error {
-	// 获取可用的工作节点
-	worker, err := s.workerService.GetAvailableWorker(ctx, "")
+// 发送任务取消消息到消息队列
+func (s *taskServiceImpl) sendCancelToQueue(taskID, workerID string) error {
+	// 创建RabbitMQ连接
+	rabbitmqService, err := rabbitmq.NewRabbitMQService(global.Config.RabbitMQ.URL)
 	if err != nil {
-		// 如果没有可用的工作节点，将任务状态设置为pending，等待后续分配
-		return global.ErrorNoAvailableWorker
-	}
-	
-	// 分配任务给工作节点
-	if err := s.workerService.AssignTaskToWorker(ctx, task.TaskID, worker.WorkerID); err != nil {
-		return err
-	}
-	
-	// 更新任务状态为assigned
-	if err := s.UpdateTaskStatus(ctx, task.TaskID, "assigned", nil, ""); err != nil {
 		return err
 	}
+	defer rabbitmqService.Close()
 	
-	// 发送任务到消息队列
-	if err := s.sendTaskToQueue(task, worker.WorkerID); err != nil {
-		// 如果发送失败，更新任务状态
-		s.UpdateTaskStatus(ctx, task.TaskID, "failed", nil, fmt.Sprintf("Failed to send task to queue: %v", err))
+	cancelData, err := json.Marshal(map[string]interface{}{
+		"task_id":   taskID,
+		"worker_id": workerID,
+		"reason":    "canceled",
+	})
+	if err != nil {
 		return err
 	}
 	
-	return nil
+	return rabbitmqService.PublishTaskCancel(cancelData)
 }
 
-// 发送任务到消息队列
-func (s *taskServiceImpl) sendTaskToQueue(task *model.Task, workerID string) error {
-	// 创建RabbitMQ连接
-	rabbitmqService, err := rabbitmq.NewRabbitMQService(global.Config.RabbitMQ.URL)
-	if err != nil {
-		return err
+// 使用默认设置创建待处理任务，超时时间和优先级取自任务类型定义
+func newTask(taskType string, accountID uint, params map[string]interface{}) *model.Task {
+	timeoutSec, priority := taskTypeDefaults(taskType)
+	return &model.Task{
+		TaskID:    generateTaskID(),
+		TaskType:  taskType,
+		AccountID: accountID,
+		Params:    params,
+		Status:    "pending",
+		Priority:  priority,
+		TimeoutSec: timeoutSec,
+		MaxAttempts: 1, // 默认不重试
+		RetryBackoffSec: 30, // 默认重试退避30秒起
+		RetryBackoffMaxSec: 1800, // 默认重试退避最长30分钟
 	}
-	defer rabbitmqService.Close()
-	
-	// 准备任务消息
-	taskMessage := map[string]interface{}{
-		"task_id":    task.TaskID,
-		"task_type":  task.TaskType,
-		"account_id": task.AccountID,
-		"params":     task.Params,
-		"worker_id":  workerID,
-		"timeout":    task.TimeoutSec,
-		"created_at": task.CreatedAt,
-	}
-	
-	// 发送任务到队列
-	return rabbitmqService.PublishMessage(
-		global.Config.RabbitMQ.Exchange.Tasks,         // 交换机
-		fmt.Sprintf("task.%s", task.TaskType),  // 路由键
-		taskMessage,                           // 消息体
-	)
 }
 
 // 生成任务ID
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// TaskStatus 任务状态
+type TaskStatus string
+
+const (
+	TaskStatusBlocked    TaskStatus = "blocked"    // 等待上游任务完成
+	TaskStatusPending    TaskStatus = "pending"    // 等待调度
+	TaskStatusPaused     TaskStatus = "paused"     // 已暂停，恢复前不会被调度
+	TaskStatusAssigned   TaskStatus = "assigned"   // 已分配给工作节点
+	TaskStatusProcessing TaskStatus = "processing" // 执行中
+	TaskStatusCanceling  TaskStatus = "canceling"  // 等待工作节点确认取消
+	TaskStatusCompleted  TaskStatus = "completed"  // 已完成
+	TaskStatusFailed     TaskStatus = "failed"     // 失败
+	TaskStatusCanceled   TaskStatus = "canceled"   // 已取消
+	TaskStatusTimeout    TaskStatus = "timeout"    // 执行超时
+	TaskStatusSkipped    TaskStatus = "skipped"    // 因上游任务失败被跳过
+)
+
+// 任务状态操作者，工作节点以其worker_id作为操作者
+const (
+	TaskActorUser      = "user"      // 用户通过API操作
+	TaskActorScheduler = "scheduler" // 调度器
+	TaskActorSystem    = "system"    // 其他系统流程
+)
+
+// 上下文中保存操作者的key
+type taskActorKey struct{}
+
+// WithTaskActor 在ctx中记录状态变化的操作者
+func WithTaskActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, taskActorKey{}, actor)
+}
+
+// 获取ctx中的操作者，未设置时为system
+func taskActor(ctx context.Context) string {
+	if actor, ok := ctx.Value(taskActorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return TaskActorSystem
+}
+
+// 允许的状态转换，终态不能再转换
+var taskTransitions = map[TaskStatus][]TaskStatus{
+	TaskStatusBlocked:    {TaskStatusPending, TaskStatusPaused, TaskStatusCanceled, TaskStatusSkipped},
+	TaskStatusPending:    {TaskStatusAssigned, TaskStatusPaused, TaskStatusCanceled},
+	TaskStatusPaused:     {TaskStatusPending, TaskStatusBlocked, TaskStatusCanceled, TaskStatusSkipped},
+	TaskStatusAssigned:   {TaskStatusProcessing, TaskStatusPending, TaskStatusCanceling, TaskStatusCompleted, TaskStatusFailed, TaskStatusTimeout},
+	TaskStatusProcessing: {TaskStatusPending, TaskStatusCanceling, TaskStatusCompleted, TaskStatusFailed, TaskStatusTimeout},
+	TaskStatusCanceling:  {TaskStatusCanceled, TaskStatusCompleted, TaskStatusFailed},
+}
+
+// CanTransition 判断任务能否从from转换到to
+func CanTransition(from, to TaskStatus) bool {
+	for _, next := range taskTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinishedStatus 判断任务状态是否为终态
+func IsFinishedStatus(status string) bool {
+	_, ok := taskTransitions[TaskStatus(status)]
+	return !ok && status != ""
+}
+
+// TransitionTaskStatus 将任务从task.Status转换到to，并写入状态历史
+// fields为需要同时更新的其他字段，以当前状态为条件更新，
+// 任务状态已被其他流程修改时返回ErrorTaskStatusConflict，转换不合法时返回ErrorInvalidTaskStatus
+func TransitionTaskStatus(db *gorm.DB, task *model.Task, to TaskStatus, actor, reason string, fields map[string]interface{}) error {
+	from := TaskStatus(task.Status)
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", global.ErrorInvalidTaskStatus, from, to)
+	}
+
+	updates := map[string]interface{}{
+		"status": string(to),
+	}
+	for field, value := range fields {
+		updates[field] = value
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Task{}).
+			Where("task_id = ? AND status = ?", task.TaskID, task.Status).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return global.ErrorTaskStatusConflict
+		}
+
+		history := model.TaskStatusHistory{
+			TaskID:     task.TaskID,
+			FromStatus: string(from),
+			ToStatus:   string(to),
+			Actor:      actor,
+			Reason:     reason,
+			ChangedAt:  time.Now(),
+		}
+		return tx.Create(&history).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	task.Status = string(to)
+	return nil
+}
+
+// 创建任务时的初始状态历史
+func newCreationHistory(task *model.Task, actor, reason string) model.TaskStatusHistory {
+	return model.TaskStatusHistory{
+		TaskID:    task.TaskID,
+		ToStatus:  task.Status,
+		Actor:     actor,
+		Reason:    reason,
+		ChangedAt: time.Now(),
+	}
+}
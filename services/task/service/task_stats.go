@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// 耗时统计的分组方式
+const (
+	TaskStatsByTaskType = "task_type" // 按任务类型
+	TaskStatsByWorker   = "worker"    // 按工作节点
+	TaskStatsByBucket   = "bucket"    // 按完成时间分段
+)
+
+const (
+	// 默认统计最近24小时
+	defaultTaskStatsRange = 24 * time.Hour
+	// 统计时间范围上限
+	maxTaskStatsRange = 31 * 24 * time.Hour
+	// 默认时间分段(秒)
+	defaultTaskStatsBucket = 3600
+	// 按时间分段统计时的分段数上限
+	maxTaskStatsBuckets = 1000
+)
+
+// TaskStatsQuery 任务耗时统计条件，按执行记录的完成时间筛选
+type TaskStatsQuery struct {
+	GroupBy   string    // 分组方式: task_type, worker, bucket
+	From      time.Time // 开始时间(含)，为空时为结束时间前24小时
+	To        time.Time // 结束时间(不含)，为空时为当前时间
+	BucketSec int       // 按时间分段时每段的秒数
+	TaskType  string    // 任务类型
+	WorkerID  string    // 工作节点ID
+}
+
+// DurationPercentiles 耗时分位数，单位毫秒
+type DurationPercentiles struct {
+	Count int   `json:"count"` // 样本数
+	P50   int64 `json:"p50"`   // 50分位
+	P95   int64 `json:"p95"`   // 95分位
+	P99   int64 `json:"p99"`   // 99分位
+	Max   int64 `json:"max"`   // 最大值
+}
+
+// TaskStatsGroup 一个分组的排队和执行耗时
+type TaskStatsGroup struct {
+	Key       string              `json:"key"`       // 任务类型、工作节点ID或时间分段的开始时间(RFC3339)
+	Count     int                 `json:"count"`     // 执行记录数
+	Wait      DurationPercentiles `json:"wait"`      // 从入队到分配的排队耗时
+	Execution DurationPercentiles `json:"execution"` // 从开始到完成的执行耗时
+}
+
+// TaskReadyAt 任务本次尝试可被调度的时间，即创建时间、计划执行时间和下次重试时间中最晚者
+func TaskReadyAt(task *model.Task) time.Time {
+	ready := task.CreatedAt
+	if task.RunAt != nil && task.RunAt.After(ready) {
+		ready = *task.RunAt
+	}
+	if task.NextRetryAt != nil && task.NextRetryAt.After(ready) {
+		ready = *task.NextRetryAt
+	}
+	return ready
+}
+
+// ApplyTaskTimings 在执行记录中写入任务类型以及本次尝试的入队、分配时间和排队耗时
+func ApplyTaskTimings(record *model.TaskRecord, task *model.Task) {
+	record.TaskType = task.TaskType
+	record.QueuedAt = task.QueuedAt
+	record.AssignedAt = task.AssignedAt
+	if task.QueuedAt != nil && task.AssignedAt != nil {
+		wait := int(task.AssignedAt.Sub(*task.QueuedAt).Milliseconds())
+		if wait < 0 {
+			wait = 0
+		}
+		record.WaitTime = &wait
+	}
+}
+
+// Percentiles 计算耗时的分位数，按最近秩法取值
+func Percentiles(values []int64) DurationPercentiles {
+	result := DurationPercentiles{Count: len(values)}
+	if len(values) == 0 {
+		return result
+	}
+
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := func(p float64) int64 {
+		idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return sorted[idx]
+	}
+
+	result.P50 = rank(0.50)
+	result.P95 = rank(0.95)
+	result.P99 = rank(0.99)
+	result.Max = sorted[len(sorted)-1]
+	return result
+}
+
+// Validate 校验统计条件并填充默认值
+func (q *TaskStatsQuery) Validate(now time.Time) error {
+	switch q.GroupBy {
+	case "":
+		q.GroupBy = TaskStatsByTaskType
+	case TaskStatsByTaskType, TaskStatsByWorker, TaskStatsByBucket:
+	default:
+		return fmt.Errorf("invalid group_by %q", q.GroupBy)
+	}
+
+	if q.To.IsZero() {
+		q.To = now
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultTaskStatsRange)
+	}
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("from must be before to")
+	}
+	if q.To.Sub(q.From) > maxTaskStatsRange {
+		return fmt.Errorf("time range must not exceed %d days", int(maxTaskStatsRange.Hours()/24))
+	}
+
+	if q.BucketSec <= 0 {
+		q.BucketSec = defaultTaskStatsBucket
+	}
+	bucket := time.Duration(q.BucketSec) * time.Second
+	if q.GroupBy == TaskStatsByBucket && (q.To.Sub(q.From)+bucket-1)/bucket > maxTaskStatsBuckets {
+		return fmt.Errorf("time range must not exceed %d buckets, increase bucket_sec", maxTaskStatsBuckets)
+	}
+	return nil
+}
+
+// TaskStatsResult 任务耗时统计结果
+type TaskStatsResult struct {
+	Groups      []*TaskStatsGroup `json:"groups"`       // 各分组的统计
+	Truncated   bool              `json:"truncated"`    // 执行记录超过采样上限，分位数仅按最近的记录计算，记录数不受影响
+	SampleLimit int               `json:"sample_limit"` // 计算分位数的执行记录数上限
+}
+
+// 统计范围内用于计算分位数的执行记录数上限，避免大范围统计时一次加载全部记录
+const maxTaskStatsSamples = 100000
+
+// taskStatsSample 计算分位数所需的执行记录字段
+type taskStatsSample struct {
+	TaskType      string
+	WorkerID      string
+	CompletedAt   time.Time
+	WaitTime      *int
+	ExecutionTime int
+}
+
+// 执行记录所属的分组
+func (q *TaskStatsQuery) groupKey(taskType, workerID string, completedAt time.Time) string {
+	switch q.GroupBy {
+	case TaskStatsByWorker:
+		return workerID
+	case TaskStatsByBucket:
+		return q.bucketKey(int64(completedAt.Sub(q.From) / (time.Duration(q.BucketSec) * time.Second)))
+	default:
+		return taskType
+	}
+}
+
+// 第index个时间分段的key
+func (q *TaskStatsQuery) bucketKey(index int64) string {
+	return q.From.Add(time.Duration(index) * time.Duration(q.BucketSec) * time.Second).Format(time.RFC3339)
+}
+
+// GetTaskSLAStats 按任务类型、工作节点或时间分段统计排队和执行耗时的分位数
+// 各分组的记录数在数据库中统计，分位数按最近的至多maxTaskStatsSamples条记录计算
+func GetTaskSLAStats(ctx context.Context, q *TaskStatsQuery) (*TaskStatsResult, error) {
+	if err := q.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+
+	scope := func() *gorm.DB {
+		db := global.DB.WithContext(ctx).Model(&model.TaskRecord{}).
+			Where("completed_at >= ? AND completed_at < ?", q.From, q.To)
+		if q.TaskType != "" {
+			db = db.Where("task_type = ?", q.TaskType)
+		}
+		if q.WorkerID != "" {
+			db = db.Where("worker_id = ?", q.WorkerID)
+		}
+		return db
+	}
+
+	// 按分组统计记录数
+	var keyExpr interface{}
+	switch q.GroupBy {
+	case TaskStatsByWorker:
+		keyExpr = gorm.Expr("worker_id")
+	case TaskStatsByBucket:
+		keyExpr = gorm.Expr("FLOOR(TIMESTAMPDIFF(SECOND, ?, completed_at) / ?)", q.From, q.BucketSec)
+	default:
+		keyExpr = gorm.Expr("task_type")
+	}
+	var counts []struct {
+		GroupKey string
+		Count    int
+	}
+	if err := scope().Select("? AS group_key, COUNT(*) AS count", keyExpr).
+		Group("group_key").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	type samples struct {
+		wait      []int64
+		execution []int64
+	}
+	groups := make(map[string]*samples, len(counts))
+	result := &TaskStatsResult{
+		Groups:      make([]*TaskStatsGroup, 0, len(counts)),
+		SampleLimit: maxTaskStatsSamples,
+	}
+	for _, count := range counts {
+		key := count.GroupKey
+		if q.GroupBy == TaskStatsByBucket {
+			index, err := strconv.ParseInt(key, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bucket %q: %w", key, err)
+			}
+			key = q.bucketKey(index)
+		}
+		groups[key] = &samples{}
+		result.Groups = append(result.Groups, &TaskStatsGroup{Key: key, Count: count.Count})
+	}
+
+	// 加载最近的执行记录计算分位数
+	var records []taskStatsSample
+	if err := scope().Select("task_type", "worker_id", "completed_at", "wait_time", "execution_time").
+		Order("completed_at DESC").
+		Limit(maxTaskStatsSamples + 1).
+		Scan(&records).Error; err != nil {
+		return nil, err
+	}
+	if len(records) > maxTaskStatsSamples {
+		records = records[:maxTaskStatsSamples]
+		result.Truncated = true
+	}
+
+	for _, record := range records {
+		group, ok := groups[q.groupKey(record.TaskType, record.WorkerID, record.CompletedAt)]
+		if !ok {
+			// 统计记录数后新写入的记录
+			continue
+		}
+		if record.WaitTime != nil {
+			group.wait = append(group.wait, int64(*record.WaitTime))
+		}
+		group.execution = append(group.execution, int64(record.ExecutionTime))
+	}
+
+	for _, group := range result.Groups {
+		group.Wait = Percentiles(groups[group.Key].wait)
+		group.Execution = Percentiles(groups[group.Key].execution)
+	}
+	// 时间分段的key为RFC3339格式，按字符串排序即按时间排序
+	sort.Slice(result.Groups, func(i, j int) bool { return result.Groups[i].Key < result.Groups[j].Key })
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"strings"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+)
+
+// SplitTags 拆分逗号分隔的标签，忽略空白标签
+func SplitTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// JoinTags 去除空白和重复的标签后以逗号拼接
+func JoinTags(tags []string) string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+	return strings.Join(result, ",")
+}
+
+// TaskRequiredTags 执行任务所需的工作节点标签，包括创建任务时声明的标签，
+// 开启require-capability时还包括任务类型要求的能力，未开启时未配置标签的工作节点仍可执行所有类型的任务
+func TaskRequiredTags(task *model.Task) []string {
+	tags := SplitTags(task.RequiredTags)
+	if !global.Config.Task.RequireCapability {
+		return tags
+	}
+	if def, ok := GetTaskType(task.TaskType); ok && def.Capability != "" {
+		tags = append([]string{def.Capability}, tags...)
+	}
+	return tags
+}
+
+// WorkerHasTags 判断工作节点是否具备全部标签，标签比较不区分大小写
+func WorkerHasTags(worker *model.Worker, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	owned := make(map[string]bool)
+	for _, tag := range SplitTags(worker.Tags) {
+		owned[strings.ToLower(tag)] = true
+	}
+	for _, tag := range tags {
+		if !owned[strings.ToLower(tag)] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"tg_manager_api/global"
+)
+
+// ParamSchema 任务参数的JSON Schema描述，仅支持常用关键字
+type ParamSchema struct {
+	Type        string                  `json:"type"`                  // 类型: object, string, integer, number, boolean, array
+	Description string                  `json:"description,omitempty"` // 说明
+	Properties  map[string]*ParamSchema `json:"properties,omitempty"`  // 对象属性
+	Required    []string                `json:"required,omitempty"`    // 必填属性
+	Enum        []interface{}           `json:"enum,omitempty"`        // 可选值
+	MinLength   *int                    `json:"minLength,omitempty"`   // 字符串最小长度
+	MaxLength   *int                    `json:"maxLength,omitempty"`   // 字符串最大长度
+	Minimum     *float64                `json:"minimum,omitempty"`     // 数值下限
+	Maximum     *float64                `json:"maximum,omitempty"`     // 数值上限
+	Items       *ParamSchema            `json:"items,omitempty"`       // 数组元素
+}
+
+// TaskTypeDefinition 任务类型定义
+type TaskTypeDefinition struct {
+	Type              string       `json:"type"`                // 任务类型
+	Name              string       `json:"name"`                // 显示名称
+	Description       string       `json:"description"`         // 说明
+	ParamSchema       *ParamSchema `json:"param_schema"`        // 参数Schema
+	DefaultTimeoutSec int          `json:"default_timeout_sec"` // 默认超时时间(秒)
+	DefaultPriority   int          `json:"default_priority"`    // 默认优先级
+	Capability        string       `json:"capability"`          // 执行该任务所需的工作节点能力
+}
+
+// ParamFieldError 单个参数字段的校验错误
+type ParamFieldError struct {
+	Field   string `json:"field"`   // 字段路径，如 params.message
+	Message string `json:"message"` // 错误说明
+}
+
+// TaskParamsError 任务参数校验错误，包含所有字段错误
+type TaskParamsError struct {
+	TaskType string            // 任务类型
+	Fields   []ParamFieldError // 字段错误
+}
+
+func (e *TaskParamsError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+	return fmt.Sprintf("invalid params for task type %s: %s", e.TaskType, strings.Join(messages, "; "))
+}
+
+func (e *TaskParamsError) Unwrap() error {
+	return global.ErrorInvalidTaskParams
+}
+
+var (
+	taskTypeRegistry = make(map[string]*TaskTypeDefinition)
+	taskTypeMutex    sync.RWMutex
+)
+
+// RegisterTaskType 注册任务类型，同名类型会被覆盖
+func RegisterTaskType(def *TaskTypeDefinition) {
+	taskTypeMutex.Lock()
+	defer taskTypeMutex.Unlock()
+	taskTypeRegistry[def.Type] = def
+}
+
+// GetTaskType 获取任务类型定义
+func GetTaskType(taskType string) (*TaskTypeDefinition, bool) {
+	taskTypeMutex.RLock()
+	defer taskTypeMutex.RUnlock()
+	def, ok := taskTypeRegistry[taskType]
+	return def, ok
+}
+
+// ListTaskTypes 获取所有已注册的任务类型，按类型名排序
+func ListTaskTypes() []*TaskTypeDefinition {
+	taskTypeMutex.RLock()
+	defer taskTypeMutex.RUnlock()
+
+	defs := make([]*TaskTypeDefinition, 0, len(taskTypeRegistry))
+	for _, def := range taskTypeRegistry {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Type < defs[j].Type
+	})
+	return defs
+}
+
+// 返回任务类型的默认超时时间和优先级，未注册或未设置时使用全局默认值
+func taskTypeDefaults(taskType string) (timeoutSec, priority int) {
+	timeoutSec = 300 // 默认5分钟超时
+	if def, ok := GetTaskType(taskType); ok {
+		if def.DefaultTimeoutSec > 0 {
+			timeoutSec = def.DefaultTimeoutSec
+		}
+		priority = def.DefaultPriority
+	}
+	return timeoutSec, priority
+}
+
+// ValidateTaskParams 校验任务类型及其参数
+// 未注册的类型返回ErrorInvalidTaskType，参数不符合Schema时返回*TaskParamsError
+func ValidateTaskParams(taskType string, params map[string]interface{}) error {
+	def, ok := GetTaskType(taskType)
+	if !ok {
+		return fmt.Errorf("%w: %s", global.ErrorInvalidTaskType, taskType)
+	}
+	if def.ParamSchema == nil {
+		return nil
+	}
+
+	var value interface{} = params
+	if params == nil {
+		value = map[string]interface{}{}
+	}
+
+	var fields []ParamFieldError
+	validateParam(def.ParamSchema, value, "params", &fields)
+	if len(fields) > 0 {
+		return &TaskParamsError{TaskType: taskType, Fields: fields}
+	}
+	return nil
+}
+
+// 按Schema递归校验参数值，错误追加到fields
+func validateParam(schema *ParamSchema, value interface{}, path string, fields *[]ParamFieldError) {
+	fail := func(format string, args ...interface{}) {
+		*fields = append(*fields, ParamFieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range schema.Required {
+			if v, exists := obj[name]; !exists || v == nil {
+				*fields = append(*fields, ParamFieldError{Field: path + "." + name, Message: "is required"})
+			}
+		}
+		// 按属性名排序，保证错误顺序稳定
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propSchema, known := schema.Properties[name]
+			if !known {
+				*fields = append(*fields, ParamFieldError{Field: path + "." + name, Message: "is not allowed"})
+				continue
+			}
+			if obj[name] != nil {
+				validateParam(propSchema, obj[name], path+"."+name, fields)
+			}
+		}
+		return
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		length := len([]rune(str))
+		if schema.MinLength != nil && length < *schema.MinLength {
+			fail("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("must be at most %d characters", *schema.MaxLength)
+		}
+	case "integer", "number":
+		num, ok := toFloat(value)
+		if !ok {
+			fail("must be a %s", schema.Type)
+			return
+		}
+		if schema.Type == "integer" && num != math.Trunc(num) {
+			fail("must be an integer")
+			return
+		}
+		if schema.Minimum != nil && num < *schema.Minimum {
+			fail("must be >= %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && num > *schema.Maximum {
+			fail("must be <= %v", *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+			return
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				validateParam(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), fields)
+			}
+		}
+		return
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		fail("must be one of %v", schema.Enum)
+	}
+}
+
+// 将JSON数值转换为float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// 判断值是否在枚举列表中
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if fmt.Sprint(candidate) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
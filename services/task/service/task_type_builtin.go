@@ -0,0 +1,121 @@
+package service
+
+// 内置任务类型，与Python工作节点支持的任务保持一致
+var builtinTaskTypes = []*TaskTypeDefinition{
+	{
+		Type:              string(TaskTypeTdataImport),
+		Name:              "Tdata导入",
+		Description:       "从tdata文件导入Telegram账号",
+		DefaultTimeoutSec: 600,
+		Capability:        "tdata",
+		ParamSchema: &ParamSchema{
+			Type:     "object",
+			Required: []string{"file_path"},
+			Properties: map[string]*ParamSchema{
+				"file_path": {Type: "string", Description: "tdata文件路径", MinLength: intPtr(1)},
+				"password":  {Type: "string", Description: "两步验证密码"},
+			},
+		},
+	},
+	{
+		Type:              string(TaskTypeSendPrivate),
+		Name:              "发送私聊消息",
+		Description:       "向指定用户发送私聊消息",
+		DefaultTimeoutSec: 120,
+		Capability:        "telegram",
+		ParamSchema: &ParamSchema{
+			Type:     "object",
+			Required: []string{"target", "message"},
+			Properties: map[string]*ParamSchema{
+				"target":     {Type: "string", Description: "目标用户名或用户ID", MinLength: intPtr(1)},
+				"message":    {Type: "string", Description: "消息内容", MinLength: intPtr(1), MaxLength: intPtr(4096)},
+				"parse_mode": {Type: "string", Description: "消息格式", Enum: []interface{}{"text", "markdown", "html"}},
+			},
+		},
+	},
+	{
+		Type:              string(TaskTypeSendGroup),
+		Name:              "发送群组消息",
+		Description:       "向指定群组或频道发送消息",
+		DefaultTimeoutSec: 120,
+		Capability:        "telegram",
+		ParamSchema: &ParamSchema{
+			Type:     "object",
+			Required: []string{"group", "message"},
+			Properties: map[string]*ParamSchema{
+				"group":      {Type: "string", Description: "群组用户名、ID或邀请链接", MinLength: intPtr(1)},
+				"message":    {Type: "string", Description: "消息内容", MinLength: intPtr(1), MaxLength: intPtr(4096)},
+				"parse_mode": {Type: "string", Description: "消息格式", Enum: []interface{}{"text", "markdown", "html"}},
+			},
+		},
+	},
+	{
+		Type:              string(TaskTypeJoinGroup),
+		Name:              "加入群组",
+		Description:       "加入指定群组或频道",
+		DefaultTimeoutSec: 120,
+		Capability:        "telegram",
+		ParamSchema: &ParamSchema{
+			Type:     "object",
+			Required: []string{"group"},
+			Properties: map[string]*ParamSchema{
+				"group": {Type: "string", Description: "群组用户名、ID或邀请链接", MinLength: intPtr(1)},
+			},
+		},
+	},
+	{
+		Type:              string(TaskTypeLeaveGroup),
+		Name:              "退出群组",
+		Description:       "退出指定群组或频道",
+		DefaultTimeoutSec: 120,
+		Capability:        "telegram",
+		ParamSchema: &ParamSchema{
+			Type:     "object",
+			Required: []string{"group"},
+			Properties: map[string]*ParamSchema{
+				"group": {Type: "string", Description: "群组用户名或ID", MinLength: intPtr(1)},
+			},
+		},
+	},
+	{
+		Type:              string(TaskTypeCollect),
+		Name:              "消息采集",
+		Description:       "采集指定群组或频道的历史消息",
+		DefaultTimeoutSec: 1800,
+		Capability:        "telegram",
+		ParamSchema: &ParamSchema{
+			Type:     "object",
+			Required: []string{"group"},
+			Properties: map[string]*ParamSchema{
+				"group": {Type: "string", Description: "群组用户名或ID", MinLength: intPtr(1)},
+				"limit": {Type: "integer", Description: "最多采集的消息数", Minimum: floatPtr(1), Maximum: floatPtr(10000)},
+				"since": {Type: "string", Description: "起始时间，RFC3339格式"},
+			},
+		},
+	},
+	{
+		Type:              string(TaskTypeCheckAccount),
+		Name:              "账号检查",
+		Description:       "检查账号是否可用及受限状态",
+		DefaultTimeoutSec: 60,
+		Capability:        "telegram",
+		ParamSchema: &ParamSchema{
+			Type:       "object",
+			Properties: map[string]*ParamSchema{},
+		},
+	},
+}
+
+func init() {
+	for _, def := range builtinTaskTypes {
+		RegisterTaskType(def)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
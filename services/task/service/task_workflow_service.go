@@ -0,0 +1,547 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/dispatch"
+)
+
+// 工作流失败策略
+const (
+	WorkflowFailureCancel = "cancel" // 任一任务失败时取消工作流中所有未开始的任务
+	WorkflowFailureSkip   = "skip"   // 任一任务失败时仅跳过其下游任务，其余分支继续执行
+)
+
+// WorkflowStepSpec 工作流步骤定义
+type WorkflowStepSpec struct {
+	Key          string                 // 步骤标识，在工作流内唯一
+	TaskType     string                 // 任务类型
+	AccountID    uint                   // 关联的账号ID
+	Params       map[string]interface{} // 任务参数
+	Priority     int                    // 优先级
+	TimeoutSec   int                    // 超时时间(秒)
+	RequiredTags []string               // 所需的工作节点标签
+	DependsOn    []string               // 依赖的步骤标识
+	WaitSec      int                    // 依赖全部完成后再等待的时间(秒)
+}
+
+// WorkflowStatus 工作流汇总状态
+type WorkflowStatus struct {
+	Workflow     *model.TaskWorkflow    `json:"workflow"`      // 工作流信息
+	Tasks        []*model.Task          `json:"tasks"`         // 工作流中的任务
+	Dependencies []model.TaskDependency `json:"dependencies"`  // 任务依赖关系
+	StatusCounts map[string]int         `json:"status_counts"` // 各状态任务数
+}
+
+// TaskWorkflowServiceI 任务工作流服务接口
+type TaskWorkflowServiceI interface {
+	// 创建工作流
+	CreateWorkflow(ctx context.Context, name, failurePolicy string, steps []WorkflowStepSpec) (*model.TaskWorkflow, error)
+
+	// 获取工作流汇总状态
+	GetWorkflowStatus(ctx context.Context, workflowID string) (*WorkflowStatus, error)
+
+	// 任务结束后释放或取消其下游任务
+	ResolveDependents(ctx context.Context, taskID string, succeeded bool) error
+}
+
+// NewTaskWorkflowService 创建任务工作流服务实例
+func NewTaskWorkflowService() TaskWorkflowServiceI {
+	return &taskWorkflowService{}
+}
+
+// taskWorkflowService 任务工作流服务实现
+type taskWorkflowService struct{}
+
+// CreateWorkflow 创建工作流
+// 没有依赖的任务直接进入pending状态，其余任务处于blocked状态等待上游完成
+func (s *taskWorkflowService) CreateWorkflow(ctx context.Context, name, failurePolicy string, steps []WorkflowStepSpec) (*model.TaskWorkflow, error) {
+	if failurePolicy == "" {
+		failurePolicy = WorkflowFailureCancel
+	}
+	if failurePolicy != WorkflowFailureCancel && failurePolicy != WorkflowFailureSkip {
+		return nil, fmt.Errorf("%w: unknown failure policy %q", global.ErrorInvalidWorkflow, failurePolicy)
+	}
+	if err := validateWorkflowSteps(steps); err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		if err := ValidateTaskParams(step.TaskType, step.Params); err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.Key, err)
+		}
+	}
+
+	// 检查账号是否存在
+	accountIDs := make([]uint, 0, len(steps))
+	seen := make(map[uint]bool)
+	for _, step := range steps {
+		if !seen[step.AccountID] {
+			seen[step.AccountID] = true
+			accountIDs = append(accountIDs, step.AccountID)
+		}
+	}
+	var accounts []model.Account
+	if err := global.DB.Select("id", "account_group_id", "account_level").
+		Where("id IN ?", accountIDs).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	if len(accounts) != len(accountIDs) {
+		return nil, global.ErrorAccountNotFound
+	}
+	accountsByID := make(map[uint]*model.Account, len(accounts))
+	for i := range accounts {
+		accountsByID[accounts[i].ID] = &accounts[i]
+	}
+
+	// 暂停调度的账号的无依赖任务创建为暂停状态
+	pausedAccountIDs, err := pausedAccounts(accountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := &model.TaskWorkflow{
+		WorkflowID:    fmt.Sprintf("wf_%s", uuid.New().String()),
+		Name:          name,
+		Status:        "pending",
+		FailurePolicy: failurePolicy,
+	}
+
+	// 为每个步骤生成任务ID
+	taskIDs := make(map[string]string, len(steps))
+	for _, step := range steps {
+		taskIDs[step.Key] = generateTaskID()
+	}
+
+	tasks := make([]*model.Task, 0, len(steps))
+	for _, step := range steps {
+		task := newTask(step.TaskType, step.AccountID, step.Params)
+		task.TaskID = taskIDs[step.Key]
+		if step.Priority != 0 {
+			task.Priority = step.Priority
+		}
+		task.WorkflowID = workflow.WorkflowID
+		task.WorkflowStep = step.Key
+		task.WaitSec = step.WaitSec
+		if step.TimeoutSec > 0 {
+			task.TimeoutSec = step.TimeoutSec
+		}
+		task.RequiredTags = JoinTags(step.RequiredTags)
+		if len(step.DependsOn) > 0 {
+			task.Status = string(TaskStatusBlocked)
+		} else if pausedAccountIDs[step.AccountID] {
+			task.Status = string(TaskStatusPaused)
+		}
+		tasks = append(tasks, task)
+	}
+
+	// 占用各步骤账号的任务配额，超出配额的步骤推迟执行，下游步骤被释放时不会早于推迟后的时间
+	deferred, err := reserveTaskQuotas(ctx, tasks, accountsByID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workflow).Error; err != nil {
+			return err
+		}
+
+		for i, step := range steps {
+			task := tasks[i]
+			if err := tx.Create(task).Error; err != nil {
+				return err
+			}
+			history := newCreationHistory(task, TaskActorUser, "Created by workflow "+workflow.WorkflowID)
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+
+			for _, parent := range step.DependsOn {
+				dependency := model.TaskDependency{
+					WorkflowID:      workflow.WorkflowID,
+					TaskID:          task.TaskID,
+					DependsOnTaskID: taskIDs[parent],
+				}
+				if err := tx.Create(&dependency).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		releaseTaskQuotas(ctx, tasks)
+		return nil, err
+	}
+	for _, task := range deferred {
+		logQuotaDeferred(task)
+	}
+
+	// 没有依赖的任务可立即调度
+	dispatch.Notify("workflow_created")
+
+	return workflow, nil
+}
+
+// GetWorkflowStatus 获取工作流汇总状态
+func (s *taskWorkflowService) GetWorkflowStatus(ctx context.Context, workflowID string) (*WorkflowStatus, error) {
+	var workflow model.TaskWorkflow
+	if err := global.DB.Where("workflow_id = ?", workflowID).First(&workflow).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, global.ErrorWorkflowNotFound
+		}
+		return nil, err
+	}
+
+	var tasks []*model.Task
+	if err := global.DB.Where("workflow_id = ?", workflowID).
+		Order("id ASC").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	var dependencies []model.TaskDependency
+	if err := global.DB.Where("workflow_id = ?", workflowID).
+		Find(&dependencies).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		counts[task.Status]++
+	}
+
+	// 汇总状态按任务当前状态实时计算
+	workflow.Status = aggregateTaskStatus(counts)
+
+	return &WorkflowStatus{
+		Workflow:     &workflow,
+		Tasks:        tasks,
+		Dependencies: dependencies,
+		StatusCounts: counts,
+	}, nil
+}
+
+// ResolveDependents 任务结束后释放或取消其下游任务
+func (s *taskWorkflowService) ResolveDependents(ctx context.Context, taskID string, succeeded bool) error {
+	var task model.Task
+	if err := global.DB.Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return global.ErrorTaskNotFound
+		}
+		return err
+	}
+
+	return resolveDependents(&task, succeeded)
+}
+
+// resolveDependents 任务成功时释放上游已全部完成的下游任务，失败时按工作流失败策略处理下游任务
+func resolveDependents(task *model.Task, succeeded bool) error {
+	if task.WorkflowID == "" {
+		return nil
+	}
+
+	var workflow model.TaskWorkflow
+	if err := global.DB.Where("workflow_id = ?", task.WorkflowID).First(&workflow).Error; err != nil {
+		return err
+	}
+
+	var err error
+	if succeeded {
+		err = releaseChildren(task)
+	} else {
+		err = abortDownstream(&workflow, task)
+	}
+	if err != nil {
+		return err
+	}
+
+	return refreshWorkflowStatus(&workflow)
+}
+
+// releaseChildren 将上游任务全部完成的下游任务放入待处理队列
+func releaseChildren(task *model.Task) error {
+	var childIDs []string
+	if err := global.DB.Model(&model.TaskDependency{}).
+		Where("depends_on_task_id = ?", task.TaskID).
+		Pluck("task_id", &childIDs).Error; err != nil {
+		return err
+	}
+
+	released := false
+	for _, childID := range childIDs {
+		ok, err := releaseChild(childID, fmt.Sprintf("Upstream task %s completed", task.TaskID))
+		if err != nil {
+			return err
+		}
+		released = released || ok
+	}
+
+	if released {
+		dispatch.Notify("workflow_released")
+	}
+	return nil
+}
+
+// 统计任务尚未完成的上游任务数
+func countUnfinishedParents(taskID string) (int64, error) {
+	var unfinished int64
+	err := global.DB.Model(&model.TaskDependency{}).
+		Joins("JOIN tasks ON tasks.task_id = task_dependencies.depends_on_task_id").
+		Where("task_dependencies.task_id = ? AND tasks.status <> ?", taskID, "completed").
+		Count(&unfinished).Error
+	return unfinished, err
+}
+
+// releaseChild 上游任务全部完成时释放下游任务，返回任务是否进入待处理队列
+// 被用户暂停的下游任务保持暂停，仅记录等待时间，恢复后直接进入待处理队列
+func releaseChild(childID, reason string) (bool, error) {
+	unfinished, err := countUnfinishedParents(childID)
+	if err != nil || unfinished > 0 {
+		return false, err
+	}
+
+	var child model.Task
+	if err := global.DB.Where("task_id = ? AND status IN ?", childID,
+		[]string{string(TaskStatusBlocked), string(TaskStatusPaused)}).First(&child).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// 因配额被推迟的任务不会因等待时间较短而提前执行
+	updates := map[string]interface{}{}
+	if child.WaitSec > 0 {
+		runAt := time.Now().Add(time.Duration(child.WaitSec) * time.Second)
+		if child.RunAt == nil || runAt.After(*child.RunAt) {
+			updates["run_at"] = runAt
+		}
+	}
+
+	if child.Status == string(TaskStatusPaused) {
+		if len(updates) == 0 {
+			return false, nil
+		}
+		return false, global.DB.Model(&model.Task{}).
+			Where("task_id = ? AND status = ?", childID, child.Status).
+			Updates(updates).Error
+	}
+
+	// 账号暂停调度时下游任务释放为暂停状态，恢复后再参与调度
+	paused, err := pausedAccounts([]uint{child.AccountID})
+	if err != nil {
+		return false, err
+	}
+	status := TaskStatusPending
+	if paused[child.AccountID] {
+		status = TaskStatusPaused
+	}
+
+	err = TransitionTaskStatus(global.DB, &child, status, TaskActorSystem, reason, updates)
+	if err == global.ErrorTaskStatusConflict {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	PublishTaskEvent(NewTaskEvent(&child, string(status)))
+	AppendTaskLog(childID, "", TaskLogInfo, "%s, released as %s", reason, status)
+	return status == TaskStatusPending, nil
+}
+
+// WorkflowAbortTarget 上游任务失败时按失败策略处理的下游任务状态及其目标状态
+// cancel策略处理整个工作流中未开始的任务，skip策略仅处理失败任务的下游任务，
+// 被暂停的任务同样未开始，需要一并处理，否则恢复后仍会执行
+func WorkflowAbortTarget(failurePolicy string) ([]string, TaskStatus) {
+	if failurePolicy == WorkflowFailureCancel {
+		return []string{string(TaskStatusBlocked), string(TaskStatusPending), string(TaskStatusPaused)}, TaskStatusCanceled
+	}
+	return []string{string(TaskStatusBlocked), string(TaskStatusPaused)}, TaskStatusSkipped
+}
+
+// abortDownstream 上游任务失败时处理下游任务
+// cancel策略取消工作流中所有未开始的任务，skip策略仅跳过失败任务的所有下游任务
+func abortDownstream(workflow *model.TaskWorkflow, task *model.Task) error {
+	statuses, target := WorkflowAbortTarget(workflow.FailurePolicy)
+	if workflow.FailurePolicy == WorkflowFailureCancel {
+		var tasks []model.Task
+		if err := global.DB.Where("workflow_id = ? AND status IN ?", workflow.WorkflowID, statuses).
+			Find(&tasks).Error; err != nil {
+			return err
+		}
+		reason := fmt.Sprintf("Workflow canceled because task %s did not complete", task.TaskID)
+		return finishDownstream(tasks, target, reason)
+	}
+
+	// 广度优先遍历所有下游任务
+	visited := map[string]bool{task.TaskID: true}
+	queue := []string{task.TaskID}
+	var descendants []string
+	for len(queue) > 0 {
+		var childIDs []string
+		if err := global.DB.Model(&model.TaskDependency{}).
+			Where("depends_on_task_id IN ?", queue).
+			Pluck("task_id", &childIDs).Error; err != nil {
+			return err
+		}
+
+		queue = queue[:0]
+		for _, childID := range childIDs {
+			if !visited[childID] {
+				visited[childID] = true
+				queue = append(queue, childID)
+				descendants = append(descendants, childID)
+			}
+		}
+	}
+
+	if len(descendants) == 0 {
+		return nil
+	}
+
+	var tasks []model.Task
+	if err := global.DB.Where("task_id IN ? AND status IN ?", descendants, statuses).Find(&tasks).Error; err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("Skipped because upstream task %s did not complete", task.TaskID)
+	return finishDownstream(tasks, target, reason)
+}
+
+// finishDownstream 将未开始的下游任务逐个转换为取消或跳过状态，并归还其占用的配额
+func finishDownstream(tasks []model.Task, status TaskStatus, reason string) error {
+	now := time.Now()
+	for i := range tasks {
+		err := TransitionTaskStatus(global.DB, &tasks[i], status, TaskActorSystem, reason, map[string]interface{}{
+			"completed_at":  now,
+			"error_message": reason,
+		})
+		if err == global.ErrorTaskStatusConflict {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		tasks[i].ErrorMessage = reason
+		PublishTaskEvent(NewTaskEvent(&tasks[i], string(status)))
+
+		// 未执行的任务归还创建工作流时占用的配额
+		releaseTaskQuota(context.Background(), &tasks[i])
+	}
+	return nil
+}
+
+// refreshWorkflowStatus 根据任务状态重新计算工作流汇总状态
+func refreshWorkflowStatus(workflow *model.TaskWorkflow) error {
+	var statuses []string
+	if err := global.DB.Model(&model.Task{}).
+		Where("workflow_id = ?", workflow.WorkflowID).
+		Pluck("status", &statuses).Error; err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, status := range statuses {
+		counts[status]++
+	}
+
+	status := aggregateTaskStatus(counts)
+	updates := map[string]interface{}{
+		"status": status,
+	}
+	if status == "completed" || status == "failed" || status == "canceled" {
+		updates["completed_at"] = time.Now()
+	}
+
+	return global.DB.Model(workflow).Updates(updates).Error
+}
+
+// aggregateTaskStatus 按各状态任务数汇总一组任务的状态
+// 仍有未结束的任务时为pending或running，全部结束后按是否存在失败任务判定
+func aggregateTaskStatus(counts map[string]int) string {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	running := counts["assigned"] + counts["processing"] + counts["canceling"]
+	unfinished := counts["blocked"] + counts["pending"] + counts["paused"] + running
+	if unfinished > 0 {
+		if unfinished == total && running == 0 {
+			return "pending"
+		}
+		return "running"
+	}
+
+	switch {
+	case counts["failed"]+counts["timeout"] > 0:
+		return "failed"
+	case counts["completed"]+counts["skipped"] == total:
+		return "completed"
+	default:
+		return "canceled"
+	}
+}
+
+// validateWorkflowSteps 校验步骤定义，依赖必须存在且不能成环
+func validateWorkflowSteps(steps []WorkflowStepSpec) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("%w: workflow has no steps", global.ErrorInvalidWorkflow)
+	}
+
+	indegree := make(map[string]int, len(steps))
+	for _, step := range steps {
+		if step.Key == "" {
+			return fmt.Errorf("%w: step key is required", global.ErrorInvalidWorkflow)
+		}
+		if _, ok := indegree[step.Key]; ok {
+			return fmt.Errorf("%w: duplicate step key %q", global.ErrorInvalidWorkflow, step.Key)
+		}
+		indegree[step.Key] = 0
+	}
+
+	children := make(map[string][]string)
+	for _, step := range steps {
+		for _, parent := range step.DependsOn {
+			if _, ok := indegree[parent]; !ok {
+				return fmt.Errorf("%w: step %q depends on unknown step %q", global.ErrorInvalidWorkflow, step.Key, parent)
+			}
+			if parent == step.Key {
+				return fmt.Errorf("%w: step %q depends on itself", global.ErrorInvalidWorkflow, step.Key)
+			}
+			children[parent] = append(children[parent], step.Key)
+			indegree[step.Key]++
+		}
+	}
+
+	// 拓扑排序检测环
+	var queue []string
+	for key, degree := range indegree {
+		if degree == 0 {
+			queue = append(queue, key)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, child := range children[key] {
+			indegree[child]--
+			if indegree[child] == 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+	if visited != len(steps) {
+		return fmt.Errorf("%w: dependency cycle detected", global.ErrorInvalidWorkflow)
+	}
+
+	return nil
+}
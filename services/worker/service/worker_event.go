@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"tg_manager_api/global"
+)
+
+// 工作节点事件的Redis发布订阅频道，所有API实例共享
+const workerEventChannel = "worker:events"
+
+// WorkerEvent 工作节点状态变化事件
+type WorkerEvent struct {
+	WorkerID      string    `json:"worker_id"`      // 工作节点ID
+	Status        string    `json:"status"`         // 工作节点新状态
+	Reason        string    `json:"reason"`         // 状态变化原因
+	LastHeartbeat time.Time `json:"last_heartbeat"` // 最后心跳时间
+	RequeuedTasks []string  `json:"requeued_tasks"` // 重新排队的任务ID
+	Time          time.Time `json:"time"`           // 事件时间
+}
+
+// PublishWorkerEvent 发布工作节点事件，发布失败仅记录日志
+func PublishWorkerEvent(event *WorkerEvent) {
+	if global.Redis == nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		global.LOG.Warn(fmt.Sprintf("Failed to marshal event of worker %s: %v", event.WorkerID, err))
+		return
+	}
+
+	if err := global.Redis.Publish(context.Background(), workerEventChannel, data).Err(); err != nil {
+		global.LOG.Warn(fmt.Sprintf("Failed to publish event of worker %s: %v", event.WorkerID, err))
+	}
+}
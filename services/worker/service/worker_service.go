@@ -6,6 +6,7 @@ import (
 	
 	"tg_manager_api/global"
 	"tg_manager_api/model"
+	"tg_manager_api/services/task/dispatch"
 )
 
 // WorkerServiceI worker服务接口
@@ -19,6 +20,9 @@ type WorkerServiceI interface {
 	// 获取可用的工作节点
 	GetAvailableWorker(ctx context.Context, tags string) (*model.Worker, error)
 	
+	// 获取所有有空闲容量的在线工作节点
+	GetAvailableWorkers(ctx context.Context) ([]*model.Worker, error)
+	
 	// 分配任务到工作节点
 	AssignTaskToWorker(ctx context.Context, taskID, workerID string) error
 	
@@ -58,6 +62,7 @@ func (s *workerService) RegisterWorker(ctx context.Context, hostname, ip string,
 			return "", err
 		}
 		
+		dispatch.Notify("worker_registered")
 		return existingWorker.WorkerID, nil
 	}
 	
@@ -78,18 +83,33 @@ func (s *workerService) RegisterWorker(ctx context.Context, hostname, ip string,
 		return "", err
 	}
 	
+	// 新节点可立即接收任务
+	dispatch.Notify("worker_registered")
 	return workerID, nil
 }
 
 // UpdateHeartbeat 更新工作节点心跳
+// 只有被存活检测标记为离线的节点恢复为在线，其他状态由管理员设置，心跳不会修改
 func (s *workerService) UpdateHeartbeat(ctx context.Context, workerID string) error {
-	// 更新工作节点心跳时间
-	result := global.DB.Model(&model.Worker{}).
-		Where("worker_id = ?", workerID).
+	// 离线节点重新上线时容量重新可用，通知调度器
+	revived := global.DB.Model(&model.Worker{}).
+		Where("worker_id = ? AND status = ?", workerID, "offline").
 		Updates(map[string]interface{}{
 			"last_heartbeat": time.Now(),
 			"status":         "online",
 		})
+	if revived.Error != nil {
+		return revived.Error
+	}
+	if revived.RowsAffected > 0 {
+		dispatch.Notify("worker_online")
+		return nil
+	}
+	
+	// 更新工作节点心跳时间
+	result := global.DB.Model(&model.Worker{}).
+		Where("worker_id = ?", workerID).
+		Update("last_heartbeat", time.Now())
 	
 	if result.Error != nil {
 		return result.Error
@@ -124,6 +144,19 @@ func (s *workerService) GetAvailableWorker(ctx context.Context, tags string) (*m
 	return &worker, nil
 }
 
+// GetAvailableWorkers 获取所有有空闲容量的在线工作节点，按当前任务负载从低到高排序
+func (s *workerService) GetAvailableWorkers(ctx context.Context) ([]*model.Worker, error) {
+	var workers []*model.Worker
+	
+	if err := global.DB.Where("status = ? AND current_tasks < max_tasks", "online").
+		Order("current_tasks ASC").
+		Find(&workers).Error; err != nil {
+		return nil, err
+	}
+	
+	return workers, nil
+}
+
 // AssignTaskToWorker 分配任务到工作节点
 func (s *workerService) AssignTaskToWorker(ctx context.Context, taskID, workerID string) error {
 	// 创建任务分配记录
@@ -0,0 +1,49 @@
+package scheduler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/services/task/scheduler"
+)
+
+// 迁移后各节点的亲和账号数
+func applyMoves(counts map[string]int, moves []scheduler.AffinityMove) map[string]int {
+	result := make(map[string]int, len(counts))
+	for worker, count := range counts {
+		result[worker] = count
+	}
+	for _, move := range moves {
+		result[move.From] -= move.Count
+		result[move.To] += move.Count
+	}
+	return result
+}
+
+// 测试新节点加入时迁移亲和关系
+func TestPlanAffinityRebalanceJoin(t *testing.T) {
+	counts := map[string]int{"w1": 6, "w2": 6}
+	moves := scheduler.PlanAffinityRebalance(counts, []string{"w1", "w2", "w3"})
+
+	assert.Equal(t, map[string]int{"w1": 4, "w2": 4, "w3": 4}, applyMoves(counts, moves))
+	assert.ElementsMatch(t, []scheduler.AffinityMove{
+		{From: "w1", To: "w3", Count: 2},
+		{From: "w2", To: "w3", Count: 2},
+	}, moves)
+}
+
+// 测试已均衡或节点不足时不迁移
+func TestPlanAffinityRebalanceBalanced(t *testing.T) {
+	assert.Empty(t, scheduler.PlanAffinityRebalance(map[string]int{"w1": 3, "w2": 2, "w3": 2}, []string{"w1", "w2", "w3"}))
+	assert.Empty(t, scheduler.PlanAffinityRebalance(map[string]int{"w1": 5}, []string{"w1"}))
+	assert.Empty(t, scheduler.PlanAffinityRebalance(nil, nil))
+}
+
+// 测试只统计在线节点
+func TestPlanAffinityRebalanceIgnoresOffline(t *testing.T) {
+	counts := map[string]int{"w1": 5, "w2": 1, "gone": 10}
+	moves := scheduler.PlanAffinityRebalance(counts, []string{"w1", "w2"})
+
+	assert.Equal(t, []scheduler.AffinityMove{{From: "w1", To: "w2", Count: 2}}, moves)
+}
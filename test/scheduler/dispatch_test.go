@@ -0,0 +1,35 @@
+package scheduler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/services/task/dispatch"
+)
+
+// 测试未配置Redis时调度信号在本实例内合并
+func TestDispatchNotifyCoalesces(t *testing.T) {
+	signals := dispatch.Signals()
+
+	// 调度前的多次触发只产生一个信号
+	for i := 0; i < 10; i++ {
+		dispatch.Notify("task_created")
+	}
+
+	select {
+	case <-signals:
+	default:
+		t.Fatal("expected a pending dispatch signal")
+	}
+
+	select {
+	case <-signals:
+		t.Fatal("expected notifications to be coalesced")
+	default:
+	}
+
+	// 信号被消费后可以再次触发
+	dispatch.Notify("worker_online")
+	assert.Len(t, signals, 1)
+}
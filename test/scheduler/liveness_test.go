@@ -0,0 +1,22 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/config"
+	"tg_manager_api/services/task/scheduler"
+)
+
+// 测试工作节点离线判定时长
+func TestLivenessTimeout(t *testing.T) {
+	// 心跳间隔乘以允许错过的次数
+	assert.Equal(t, 50*time.Second, scheduler.LivenessTimeout(config.Worker{HeartbeatInterval: 10, MissedHeartbeats: 5}))
+
+	// 未配置时使用默认的30秒间隔和3次
+	assert.Equal(t, 90*time.Second, scheduler.LivenessTimeout(config.Worker{}))
+	assert.Equal(t, 30*time.Second, scheduler.LivenessTimeout(config.Worker{HeartbeatInterval: 10}))
+	assert.Equal(t, 60*time.Second, scheduler.LivenessTimeout(config.Worker{MissedHeartbeats: 2}))
+}
@@ -0,0 +1,20 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/services/task/scheduler"
+)
+
+// 测试发件箱重试间隔
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, scheduler.OutboxBackoff(0))
+	assert.Equal(t, time.Second, scheduler.OutboxBackoff(1))
+	assert.Equal(t, 2*time.Second, scheduler.OutboxBackoff(2))
+	assert.Equal(t, 8*time.Second, scheduler.OutboxBackoff(4))
+	assert.Equal(t, 5*time.Minute, scheduler.OutboxBackoff(10))
+	assert.Equal(t, 5*time.Minute, scheduler.OutboxBackoff(100))
+}
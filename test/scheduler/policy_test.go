@@ -0,0 +1,118 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/config"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/scheduler"
+)
+
+// 创建待调度任务
+func pendingTask(id uint, groupID uint, priority int, createdAt time.Time) scheduler.PendingTask {
+	task := scheduler.PendingTask{AccountGroupID: groupID}
+	task.ID = id
+	task.Priority = priority
+	task.CreatedAt = createdAt
+	return task
+}
+
+// 获取任务ID顺序
+func taskIDs(tasks []scheduler.PendingTask) []uint {
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+// 测试优先级老化
+func TestEffectivePriority(t *testing.T) {
+	now := time.Now()
+	task := &model.Task{Priority: 1}
+	task.CreatedAt = now.Add(-25 * time.Minute)
+
+	// 未开启老化
+	assert.Equal(t, 1, scheduler.EffectivePriority(task, 0, now))
+
+	// 每等待10分钟加1
+	assert.Equal(t, 3, scheduler.EffectivePriority(task, 10*time.Minute, now))
+
+	// 从下次重试时间开始计算等待时间
+	retryAt := now.Add(-5 * time.Minute)
+	task.NextRetryAt = &retryAt
+	assert.Equal(t, 1, scheduler.EffectivePriority(task, 10*time.Minute, now))
+}
+
+// 测试优先级策略
+func TestPriorityPolicy(t *testing.T) {
+	now := time.Now()
+	policy, err := scheduler.NewSchedulingPolicy(config.TaskScheduling{Policy: scheduler.PolicyPriority, AgingInterval: 600})
+	assert.NoError(t, err)
+
+	tasks := []scheduler.PendingTask{
+		pendingTask(1, 1, 0, now.Add(-time.Hour)), // 等待1小时，有效优先级6
+		pendingTask(2, 1, 5, now),
+		pendingTask(3, 1, 5, now),
+	}
+	assert.Equal(t, []uint{1, 2, 3}, taskIDs(policy.Order(tasks, now)))
+}
+
+// 测试加权公平调度
+func TestFairPolicy(t *testing.T) {
+	now := time.Now()
+	policy, err := scheduler.NewSchedulingPolicy(config.TaskScheduling{
+		Policy:  scheduler.PolicyFair,
+		FairKey: scheduler.FairKeyAccountGroup,
+		Weights: map[string]int{"2": 2},
+	})
+	assert.NoError(t, err)
+
+	// 分组1有大量高优先级任务，不会饿死分组2和分组3
+	var tasks []scheduler.PendingTask
+	for id := uint(1); id <= 6; id++ {
+		tasks = append(tasks, pendingTask(id, 1, 10, now))
+	}
+	for id := uint(7); id <= 10; id++ {
+		tasks = append(tasks, pendingTask(id, 2, 0, now))
+	}
+	tasks = append(tasks, pendingTask(11, 3, 0, now))
+
+	ordered := taskIDs(policy.Order(tasks, now))
+	assert.Len(t, ordered, 11)
+
+	// 前8个任务中分组2(权重2)分得4个，分组1和分组3共分得4个
+	group := map[uint]int{}
+	for _, id := range ordered[:8] {
+		switch {
+		case id <= 6:
+			group[1]++
+		case id <= 10:
+			group[2]++
+		default:
+			group[3]++
+		}
+	}
+	assert.Equal(t, 4, group[2])
+	assert.Equal(t, 1, group[3])
+	assert.Equal(t, 3, group[1])
+
+	// 权重最高的分组先分配，队列内保持原有顺序
+	assert.Equal(t, uint(7), ordered[0])
+	var group1 []uint
+	for _, id := range ordered {
+		if id <= 6 {
+			group1 = append(group1, id)
+		}
+	}
+	assert.Equal(t, []uint{1, 2, 3, 4, 5, 6}, group1)
+}
+
+// 测试未知调度策略
+func TestUnknownSchedulingPolicy(t *testing.T) {
+	_, err := scheduler.NewSchedulingPolicy(config.TaskScheduling{Policy: "lottery"})
+	assert.Error(t, err)
+}
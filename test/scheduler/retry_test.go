@@ -0,0 +1,47 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/scheduler"
+)
+
+// 测试重试退避时间计算
+func TestRetryDelay(t *testing.T) {
+	// 按尝试次数指数递增
+	assert.Equal(t, 30*time.Second, scheduler.RetryDelay(30, 1800, 1))
+	assert.Equal(t, 60*time.Second, scheduler.RetryDelay(30, 1800, 2))
+	assert.Equal(t, 120*time.Second, scheduler.RetryDelay(30, 1800, 3))
+	
+	// 不超过退避上限
+	assert.Equal(t, 1800*time.Second, scheduler.RetryDelay(30, 1800, 10))
+	assert.Equal(t, 1800*time.Second, scheduler.RetryDelay(30, 1800, 100))
+	
+	// 未设置退避基数时立即重试
+	assert.Equal(t, time.Duration(0), scheduler.RetryDelay(0, 1800, 3))
+}
+
+// 测试可重试判断
+func TestIsRetryable(t *testing.T) {
+	task := &model.Task{
+		MaxAttempts: 3,
+		Attempt:     1,
+	}
+	
+	// 未限定错误类型时所有错误均可重试
+	assert.True(t, scheduler.IsRetryable(task, "NETWORK"))
+	
+	// 仅重试指定的错误类型
+	task.RetryableErrors = "FLOOD_WAIT, TIMEOUT"
+	assert.True(t, scheduler.IsRetryable(task, "flood_wait"))
+	assert.True(t, scheduler.IsRetryable(task, scheduler.ErrorClassTimeout))
+	assert.False(t, scheduler.IsRetryable(task, "ACCOUNT_BANNED"))
+	
+	// 尝试次数用尽后不再重试
+	task.Attempt = 3
+	assert.False(t, scheduler.IsRetryable(task, "FLOOD_WAIT"))
+}
@@ -0,0 +1,27 @@
+package task_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/config"
+	"tg_manager_api/services/task/service"
+)
+
+// 测试归档状态只包含已结束状态
+func TestArchiveStatuses(t *testing.T) {
+	assert.ElementsMatch(t, []string{"completed", "failed", "canceled", "timeout", "skipped"},
+		service.ArchiveStatuses(config.TaskRetention{}))
+
+	// 仍在处理的状态被忽略
+	assert.Equal(t, []string{"completed", "canceled"},
+		service.ArchiveStatuses(config.TaskRetention{Statuses: []string{"completed", "pending", "processing", "canceled"}}))
+	assert.Empty(t, service.ArchiveStatuses(config.TaskRetention{Statuses: []string{"pending"}}))
+}
+
+// 测试归档批次大小
+func TestArchiveBatchSize(t *testing.T) {
+	assert.Equal(t, 500, service.ArchiveBatchSize(config.TaskRetention{}))
+	assert.Equal(t, 100, service.ArchiveBatchSize(config.TaskRetention{BatchSize: 100}))
+}
@@ -0,0 +1,16 @@
+package task_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/services/task/service"
+)
+
+// 测试批量创建时账号ID去重
+func TestUniqueAccountIDs(t *testing.T) {
+	assert.Equal(t, []uint{3, 1, 2}, service.UniqueAccountIDs([]uint{3, 1, 3, 2, 1}))
+	assert.Equal(t, []uint{5}, service.UniqueAccountIDs([]uint{5, 5, 5}))
+	assert.Empty(t, service.UniqueAccountIDs(nil))
+}
@@ -0,0 +1,57 @@
+package task_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/service"
+)
+
+// 测试查询参数默认值和排序字段校验
+func TestTaskQueryValidate(t *testing.T) {
+	query := &service.TaskQuery{}
+	assert.NoError(t, query.Validate())
+	assert.Equal(t, service.TaskSortCreatedAt, query.SortBy)
+	assert.Equal(t, 1, query.Page)
+	assert.Equal(t, 10, query.PageSize)
+
+	query = &service.TaskQuery{SortBy: "error_message"}
+	assert.Error(t, query.Validate())
+}
+
+// 测试游标只能用于生成它的排序方式
+func TestTaskQueryCursor(t *testing.T) {
+	completedAt := time.Date(2024, 5, 1, 12, 30, 0, 123, time.UTC)
+	task := &model.Task{Priority: 5, CompletedAt: &completedAt}
+	task.ID = 42
+
+	for _, sortBy := range []string{service.TaskSortCreatedAt, service.TaskSortPriority, service.TaskSortCompletedAt} {
+		cursor := service.EncodeTaskCursor(sortBy, true, task)
+
+		query := &service.TaskQuery{SortBy: sortBy, SortDesc: true, Cursor: &cursor}
+		assert.NoError(t, query.Validate(), sortBy)
+
+		// 排序方向不同
+		query = &service.TaskQuery{SortBy: sortBy, Cursor: &cursor}
+		assert.Equal(t, global.ErrorInvalidCursor, query.Validate(), sortBy)
+	}
+
+	// 排序字段不同
+	cursor := service.EncodeTaskCursor(service.TaskSortPriority, false, task)
+	query := &service.TaskQuery{SortBy: service.TaskSortCompletedAt, Cursor: &cursor}
+	assert.Equal(t, global.ErrorInvalidCursor, query.Validate())
+
+	// 非法游标
+	invalid := "not-a-cursor"
+	query = &service.TaskQuery{Cursor: &invalid}
+	assert.Equal(t, global.ErrorInvalidCursor, query.Validate())
+
+	// 空游标表示第一页
+	empty := ""
+	query = &service.TaskQuery{Cursor: &empty}
+	assert.NoError(t, query.Validate())
+}
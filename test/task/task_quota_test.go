@@ -0,0 +1,35 @@
+package task_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/config"
+	"tg_manager_api/services/task/service"
+)
+
+// 测试配额的覆盖顺序
+func TestTaskQuotaLimit(t *testing.T) {
+	// 配置文件加载后key为小写
+	cfg := config.TaskQuota{
+		Limits: map[string]int{"send_private": 30, "join_group": 10},
+		Levels: map[string]map[string]int{"3": {"send_private": 60}},
+		Groups: map[string]map[string]int{"7": {"join_group": 5, "send_private": 0}},
+	}
+
+	// 默认配额，任务类型不区分大小写
+	assert.Equal(t, 30, service.TaskQuotaLimit(cfg, "SEND_PRIVATE", 1, 1))
+	assert.Equal(t, 10, service.TaskQuotaLimit(cfg, "JOIN_GROUP", 1, 3))
+
+	// 账号等级覆盖默认配额
+	assert.Equal(t, 60, service.TaskQuotaLimit(cfg, "SEND_PRIVATE", 1, 3))
+
+	// 账号分组优先于账号等级，配置为0表示不限制
+	assert.Equal(t, 5, service.TaskQuotaLimit(cfg, "JOIN_GROUP", 7, 3))
+	assert.Equal(t, 0, service.TaskQuotaLimit(cfg, "SEND_PRIVATE", 7, 3))
+
+	// 未配置的任务类型不限制
+	assert.Equal(t, 0, service.TaskQuotaLimit(cfg, "COLLECT", 1, 1))
+	assert.Equal(t, 0, service.TaskQuotaLimit(config.TaskQuota{}, "SEND_PRIVATE", 1, 1))
+}
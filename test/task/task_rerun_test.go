@@ -0,0 +1,65 @@
+package task_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/services/task/service"
+)
+
+// 测试重新执行时的参数覆盖
+func TestMergeRerunParams(t *testing.T) {
+	origin := map[string]interface{}{
+		"chat_id": "123",
+		"message": "hello",
+		"silent":  true,
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string]interface{}
+		expected  map[string]interface{}
+	}{
+		{
+			name:      "no overrides",
+			overrides: nil,
+			expected:  map[string]interface{}{"chat_id": "123", "message": "hello", "silent": true},
+		},
+		{
+			name:      "replace and add",
+			overrides: map[string]interface{}{"message": "world", "delay": float64(5)},
+			expected:  map[string]interface{}{"chat_id": "123", "message": "world", "silent": true, "delay": float64(5)},
+		},
+		{
+			name:      "null deletes key",
+			overrides: map[string]interface{}{"silent": nil},
+			expected:  map[string]interface{}{"chat_id": "123", "message": "hello"},
+		},
+		{
+			name:      "null for missing key",
+			overrides: map[string]interface{}{"delay": nil},
+			expected:  map[string]interface{}{"chat_id": "123", "message": "hello", "silent": true},
+		},
+		{
+			name:      "false is kept",
+			overrides: map[string]interface{}{"silent": false},
+			expected:  map[string]interface{}{"chat_id": "123", "message": "hello", "silent": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, service.MergeRerunParams(origin, tt.overrides))
+		})
+	}
+
+	// 原任务参数不被修改
+	assert.Equal(t, map[string]interface{}{"chat_id": "123", "message": "hello", "silent": true}, origin)
+}
+
+// 测试原任务没有参数时的覆盖
+func TestMergeRerunParamsEmptyOrigin(t *testing.T) {
+	merged := service.MergeRerunParams(nil, map[string]interface{}{"chat_id": "123", "message": nil})
+	assert.Equal(t, map[string]interface{}{"chat_id": "123"}, merged)
+}
@@ -0,0 +1,83 @@
+package task_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/services/task/service"
+)
+
+// 测试按时区计算Cron下次执行时间
+func TestNextRunTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		cronExpr string
+		timezone string
+		after    time.Time
+		want     time.Time
+	}{
+		{
+			name:     "当天已过执行时间时顺延到次日",
+			cronExpr: "0 9 * * *",
+			timezone: "Asia/Shanghai",
+			after:    time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC), // 上海时间10:00
+			want:     time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "未设置时区时使用默认时区",
+			cronExpr: "0 9 * * *",
+			timezone: "",
+			after:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), // 上海时间08:00
+			want:     time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "按计划所在时区而不是UTC计算",
+			cronExpr: "0 9 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), // 纽约时间07:00
+			want:     time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "夏令时开始后保持当地时间不变",
+			cronExpr: "0 9 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2024, 3, 9, 15, 0, 0, 0, time.UTC),  // 纽约时间10:00 EST
+			want:     time.Date(2024, 3, 10, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+		},
+		{
+			name:     "夏令时跳过的时间当天不执行",
+			cronExpr: "30 2 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC),  // 纽约时间00:00 EST
+			want:     time.Date(2024, 3, 11, 6, 30, 0, 0, time.UTC), // 次日02:30 EDT
+		},
+		{
+			name:     "夏令时结束时重复的时间首次出现即执行",
+			cronExpr: "30 1 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2024, 11, 3, 5, 0, 0, 0, time.UTC),  // 纽约时间01:00 EDT
+			want:     time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := service.NextRunTime(tt.cronExpr, tt.timezone, tt.after)
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(next), "want %s, got %s", tt.want, next)
+		})
+	}
+}
+
+// 测试无效的Cron表达式和时区
+func TestNextRunTimeInvalid(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := service.NextRunTime("0 9 * *", "Asia/Shanghai", now)
+	assert.Error(t, err)
+
+	_, err = service.NextRunTime("0 9 * * *", "Mars/Olympus", now)
+	assert.Error(t, err)
+}
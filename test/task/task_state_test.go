@@ -0,0 +1,74 @@
+package task_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/services/task/service"
+)
+
+// 测试任务状态转换规则
+func TestCanTransition(t *testing.T) {
+	// 正常的执行流程
+	assert.True(t, service.CanTransition(service.TaskStatusBlocked, service.TaskStatusPending))
+	assert.True(t, service.CanTransition(service.TaskStatusPending, service.TaskStatusAssigned))
+	assert.True(t, service.CanTransition(service.TaskStatusAssigned, service.TaskStatusProcessing))
+	assert.True(t, service.CanTransition(service.TaskStatusProcessing, service.TaskStatusCompleted))
+
+	// 重试和取消
+	assert.True(t, service.CanTransition(service.TaskStatusProcessing, service.TaskStatusPending))
+	assert.True(t, service.CanTransition(service.TaskStatusProcessing, service.TaskStatusCanceling))
+	assert.True(t, service.CanTransition(service.TaskStatusCanceling, service.TaskStatusCanceled))
+
+	// 暂停和恢复，只有尚未分配的任务可以暂停
+	assert.True(t, service.CanTransition(service.TaskStatusPending, service.TaskStatusPaused))
+	assert.True(t, service.CanTransition(service.TaskStatusPaused, service.TaskStatusPending))
+	assert.True(t, service.CanTransition(service.TaskStatusPaused, service.TaskStatusCanceled))
+	assert.True(t, service.CanTransition(service.TaskStatusPaused, service.TaskStatusSkipped))
+	assert.True(t, service.CanTransition(service.TaskStatusBlocked, service.TaskStatusPaused))
+	// 等待上游时被暂停的任务在上游完成前恢复为等待上游状态
+	assert.True(t, service.CanTransition(service.TaskStatusPaused, service.TaskStatusBlocked))
+	assert.False(t, service.CanTransition(service.TaskStatusPaused, service.TaskStatusAssigned))
+	assert.False(t, service.CanTransition(service.TaskStatusProcessing, service.TaskStatusPaused))
+
+	// 终态不能再转换
+	assert.False(t, service.CanTransition(service.TaskStatusCompleted, service.TaskStatusProcessing))
+	assert.False(t, service.CanTransition(service.TaskStatusCanceled, service.TaskStatusPending))
+	assert.False(t, service.CanTransition(service.TaskStatusTimeout, service.TaskStatusAssigned))
+
+	// 不能跳过分配直接执行
+	assert.False(t, service.CanTransition(service.TaskStatusPending, service.TaskStatusProcessing))
+	assert.False(t, service.CanTransition(service.TaskStatusBlocked, service.TaskStatusAssigned))
+}
+
+// 测试终态判断
+func TestIsFinishedStatus(t *testing.T) {
+	for _, status := range []service.TaskStatus{
+		service.TaskStatusCompleted, service.TaskStatusFailed, service.TaskStatusCanceled,
+		service.TaskStatusTimeout, service.TaskStatusSkipped,
+	} {
+		assert.True(t, service.IsFinishedStatus(string(status)), status)
+	}
+	for _, status := range []service.TaskStatus{
+		service.TaskStatusBlocked, service.TaskStatusPending, service.TaskStatusAssigned,
+		service.TaskStatusProcessing, service.TaskStatusCanceling, service.TaskStatusPaused,
+	} {
+		assert.False(t, service.IsFinishedStatus(string(status)), status)
+	}
+}
+
+// 测试暂停任务恢复后的状态
+func TestResumeStatus(t *testing.T) {
+	// 上游任务全部完成或不属于工作流的任务进入待处理队列
+	assert.Equal(t, service.TaskStatusPending, service.ResumeStatus(0))
+
+	// 等待上游时被暂停的任务不能在上游完成前被分配
+	assert.Equal(t, service.TaskStatusBlocked, service.ResumeStatus(1))
+	assert.Equal(t, service.TaskStatusBlocked, service.ResumeStatus(3))
+
+	// 恢复后的状态都必须能从暂停状态转换
+	for _, unfinished := range []int64{0, 1} {
+		assert.True(t, service.CanTransition(service.TaskStatusPaused, service.ResumeStatus(unfinished)))
+	}
+}
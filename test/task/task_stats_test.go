@@ -0,0 +1,88 @@
+package task_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/service"
+)
+
+// 测试耗时分位数
+func TestPercentiles(t *testing.T) {
+	values := make([]int64, 0, 100)
+	for i := 100; i >= 1; i-- {
+		values = append(values, int64(i))
+	}
+
+	result := service.Percentiles(values)
+	assert.Equal(t, service.DurationPercentiles{Count: 100, P50: 50, P95: 95, P99: 99, Max: 100}, result)
+	assert.Equal(t, int64(100), values[0], "input must not be reordered")
+
+	assert.Equal(t, service.DurationPercentiles{Count: 1, P50: 7, P95: 7, P99: 7, Max: 7}, service.Percentiles([]int64{7}))
+	assert.Equal(t, service.DurationPercentiles{}, service.Percentiles(nil))
+}
+
+// 测试统计条件默认值和校验
+func TestTaskStatsQueryValidate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	q := &service.TaskStatsQuery{}
+	assert.NoError(t, q.Validate(now))
+	assert.Equal(t, service.TaskStatsByTaskType, q.GroupBy)
+	assert.Equal(t, now, q.To)
+	assert.Equal(t, now.Add(-24*time.Hour), q.From)
+	assert.Equal(t, 3600, q.BucketSec)
+
+	assert.Error(t, (&service.TaskStatsQuery{GroupBy: "account"}).Validate(now))
+	assert.Error(t, (&service.TaskStatsQuery{From: now, To: now}).Validate(now))
+	assert.Error(t, (&service.TaskStatsQuery{From: now.Add(-40 * 24 * time.Hour), To: now}).Validate(now))
+
+	// 按时间分段统计时分段数不能超过上限
+	month := now.Add(-31 * 24 * time.Hour)
+	assert.Error(t, (&service.TaskStatsQuery{GroupBy: service.TaskStatsByBucket, From: month, To: now, BucketSec: 1}).Validate(now))
+	assert.Error(t, (&service.TaskStatsQuery{GroupBy: service.TaskStatsByBucket, From: now.Add(-1001 * time.Second), To: now, BucketSec: 1}).Validate(now))
+	assert.NoError(t, (&service.TaskStatsQuery{GroupBy: service.TaskStatsByBucket, From: now.Add(-1000 * time.Second), To: now, BucketSec: 1}).Validate(now))
+	assert.NoError(t, (&service.TaskStatsQuery{GroupBy: service.TaskStatsByBucket, From: month, To: now}).Validate(now))
+
+	// 不按时间分段时不限制分段秒数
+	assert.NoError(t, (&service.TaskStatsQuery{GroupBy: service.TaskStatsByWorker, From: month, To: now, BucketSec: 1}).Validate(now))
+}
+
+// 测试执行记录的排队耗时
+func TestApplyTaskTimings(t *testing.T) {
+	queuedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assignedAt := queuedAt.Add(1500 * time.Millisecond)
+	task := &model.Task{TaskType: "SEND_PRIVATE", QueuedAt: &queuedAt, AssignedAt: &assignedAt}
+
+	var record model.TaskRecord
+	service.ApplyTaskTimings(&record, task)
+	assert.Equal(t, "SEND_PRIVATE", record.TaskType)
+	if assert.NotNil(t, record.WaitTime) {
+		assert.Equal(t, 1500, *record.WaitTime)
+	}
+
+	// 缺少分配时间时不记录排队耗时
+	record = model.TaskRecord{}
+	service.ApplyTaskTimings(&record, &model.Task{QueuedAt: &queuedAt})
+	assert.Nil(t, record.WaitTime)
+}
+
+// 测试任务可被调度的时间
+func TestTaskReadyAt(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	runAt := createdAt.Add(time.Hour)
+	retryAt := createdAt.Add(2 * time.Hour)
+
+	task := &model.Task{}
+	task.CreatedAt = createdAt
+	assert.Equal(t, createdAt, service.TaskReadyAt(task))
+
+	task.RunAt = &runAt
+	assert.Equal(t, runAt, service.TaskReadyAt(task))
+
+	task.NextRetryAt = &retryAt
+	assert.Equal(t, retryAt, service.TaskReadyAt(task))
+}
@@ -0,0 +1,49 @@
+package task_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/global"
+	"tg_manager_api/model"
+	"tg_manager_api/services/task/service"
+)
+
+// 测试标签拆分和拼接
+func TestSplitJoinTags(t *testing.T) {
+	assert.Equal(t, []string{"proxy", "gpu"}, service.SplitTags(" proxy, ,gpu,"))
+	assert.Nil(t, service.SplitTags(""))
+	assert.Equal(t, "proxy,GPU", service.JoinTags([]string{"proxy", " GPU", "Proxy", "", "gpu "}))
+}
+
+// 测试工作节点标签匹配
+func TestWorkerHasTags(t *testing.T) {
+	worker := &model.Worker{Tags: "Proxy,region-eu"}
+
+	assert.True(t, service.WorkerHasTags(worker, nil))
+	assert.True(t, service.WorkerHasTags(worker, []string{"proxy", "REGION-EU"}))
+	assert.False(t, service.WorkerHasTags(worker, []string{"proxy", "gpu"}))
+	assert.False(t, service.WorkerHasTags(&model.Worker{}, []string{"proxy"}))
+}
+
+// 测试任务所需标签仅在开启require-capability时包含任务类型要求的能力
+func TestTaskRequiredTags(t *testing.T) {
+	defer func(required bool) { global.Config.Task.RequireCapability = required }(global.Config.Task.RequireCapability)
+
+	// 默认不要求能力标签，未配置标签的工作节点仍可执行
+	global.Config.Task.RequireCapability = false
+	task := &model.Task{TaskType: string(service.TaskTypeSendPrivate)}
+	assert.Empty(t, service.TaskRequiredTags(task))
+	assert.True(t, service.WorkerHasTags(&model.Worker{}, service.TaskRequiredTags(task)))
+
+	task = &model.Task{TaskType: string(service.TaskTypeSendPrivate), RequiredTags: "proxy"}
+	assert.Equal(t, []string{"proxy"}, service.TaskRequiredTags(task))
+
+	global.Config.Task.RequireCapability = true
+	task = &model.Task{TaskType: "NOT_A_TYPE", RequiredTags: "proxy,region-eu"}
+	assert.Equal(t, []string{"proxy", "region-eu"}, service.TaskRequiredTags(task))
+
+	task = &model.Task{TaskType: string(service.TaskTypeSendPrivate), RequiredTags: "proxy"}
+	assert.Equal(t, []string{"telegram", "proxy"}, service.TaskRequiredTags(task))
+}
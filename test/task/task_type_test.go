@@ -0,0 +1,72 @@
+package task_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/global"
+	"tg_manager_api/services/task/service"
+)
+
+// 测试未注册的任务类型
+func TestValidateTaskParamsUnknownType(t *testing.T) {
+	err := service.ValidateTaskParams("NOT_A_TYPE", map[string]interface{}{})
+	assert.True(t, errors.Is(err, global.ErrorInvalidTaskType))
+}
+
+// 测试任务参数校验
+func TestValidateTaskParams(t *testing.T) {
+	// 参数合法
+	err := service.ValidateTaskParams(string(service.TaskTypeSendPrivate), map[string]interface{}{
+		"target":     "@someone",
+		"message":    "hello",
+		"parse_mode": "markdown",
+	})
+	assert.NoError(t, err)
+
+	// 缺少必填字段、类型错误、未知字段和枚举值错误
+	err = service.ValidateTaskParams(string(service.TaskTypeSendPrivate), map[string]interface{}{
+		"message":    123.0,
+		"parse_mode": "rtf",
+		"extra":      true,
+	})
+	var paramsErr *service.TaskParamsError
+	assert.True(t, errors.As(err, &paramsErr))
+	assert.True(t, errors.Is(err, global.ErrorInvalidTaskParams))
+	assert.ElementsMatch(t, []service.ParamFieldError{
+		{Field: "params.target", Message: "is required"},
+		{Field: "params.message", Message: "must be a string"},
+		{Field: "params.parse_mode", Message: "must be one of [text markdown html]"},
+		{Field: "params.extra", Message: "is not allowed"},
+	}, paramsErr.Fields)
+
+	// 整数及范围校验
+	err = service.ValidateTaskParams(string(service.TaskTypeCollect), map[string]interface{}{
+		"group": "my_group",
+		"limit": 1.5,
+	})
+	assert.True(t, errors.As(err, &paramsErr))
+	assert.Equal(t, []service.ParamFieldError{{Field: "params.limit", Message: "must be an integer"}}, paramsErr.Fields)
+
+	err = service.ValidateTaskParams(string(service.TaskTypeCollect), map[string]interface{}{
+		"group": "my_group",
+		"limit": 20000.0,
+	})
+	assert.True(t, errors.As(err, &paramsErr))
+	assert.Equal(t, []service.ParamFieldError{{Field: "params.limit", Message: "must be <= 10000"}}, paramsErr.Fields)
+}
+
+// 测试任务类型列表
+func TestListTaskTypes(t *testing.T) {
+	types := service.ListTaskTypes()
+	assert.NotEmpty(t, types)
+	for i := 1; i < len(types); i++ {
+		assert.Less(t, types[i-1].Type, types[i].Type)
+	}
+
+	def, ok := service.GetTaskType(string(service.TaskTypeCollect))
+	assert.True(t, ok)
+	assert.Equal(t, 1800, def.DefaultTimeoutSec)
+}
@@ -0,0 +1,30 @@
+package task_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tg_manager_api/services/task/service"
+)
+
+// 测试工作流失败策略处理的下游任务
+func TestWorkflowAbortTarget(t *testing.T) {
+	// cancel策略取消所有未开始的任务，包括被暂停的任务
+	statuses, target := service.WorkflowAbortTarget(service.WorkflowFailureCancel)
+	assert.ElementsMatch(t, []string{"blocked", "pending", "paused"}, statuses)
+	assert.Equal(t, service.TaskStatusCanceled, target)
+
+	// skip策略跳过等待上游和被暂停的下游任务
+	statuses, target = service.WorkflowAbortTarget(service.WorkflowFailureSkip)
+	assert.ElementsMatch(t, []string{"blocked", "paused"}, statuses)
+	assert.Equal(t, service.TaskStatusSkipped, target)
+
+	// 处理的任务都必须能转换到目标状态
+	for _, policy := range []string{service.WorkflowFailureCancel, service.WorkflowFailureSkip} {
+		statuses, target := service.WorkflowAbortTarget(policy)
+		for _, status := range statuses {
+			assert.True(t, service.CanTransition(service.TaskStatus(status), target), "%s: %s -> %s", policy, status, target)
+		}
+	}
+}